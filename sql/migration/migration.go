@@ -0,0 +1,236 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+// A Migration changes the schema from the previous version to the Version.
+type Migration struct {
+	// Version must be unique and positive, migrations are applied in the ascending order of versions.
+	Version int64
+	Name    string
+	// The statements are executed in order, such as sqlbuilder.CreateTable.
+	Up []sqlbuilder.Clause
+	// The statements to revert the Up, the migration is irreversible if it's empty.
+	Down []sqlbuilder.Clause
+	// Execute the statements without a transaction, such as "CREATE INDEX CONCURRENTLY" of PostgreSQL.
+	// It's not needed for sqlbuilder.CreateIndex and sqlbuilder.DropIndex, which are detected by the Runner.
+	NoTransaction bool
+}
+
+/*
+Checksum of the Up statements for the dialect, it detects edited migrations.
+The statements are hashed in a canonical form: the SQL parsed in Compact mode with whitespaces normalized,
+and the arguments with their types. So that the checksums of applied migrations are not changed by formatting.
+*/
+func (m *Migration) Checksum(dialect sqlbuilder.Dialect) (string, error) {
+	h := sha256.New()
+	for _, c := range m.Up {
+		sql, args, err := sqlbuilder.Build(c, dialect, sqlbuilder.Compact)
+		if err != nil {
+			return "", fmt.Errorf("migration %d: %w", m.Version, err)
+		}
+		_, _ = fmt.Fprintf(h, "%q\n", canonicalSQL(sql))
+		for _, arg := range args {
+			_, _ = fmt.Fprintf(h, "%T %q\n", arg, fmt.Sprint(arg))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Collapse whitespaces outside quotes into a single space, and drop the ones inside brackets and before commas.
+func canonicalSQL(sql string) string {
+	var builder strings.Builder
+	builder.Grow(len(sql))
+	space := false
+	for i := 0; i < len(sql); i++ {
+		switch ch := sql[i]; ch {
+		case ' ', '\t', '\n', '\r':
+			space = true
+		default:
+			prev := byte(0)
+			if builder.Len() > 0 {
+				prev = builder.String()[builder.Len()-1]
+			}
+			if space && prev != 0 && prev != '(' && ch != ')' && ch != ',' {
+				builder.WriteByte(' ')
+			}
+			space = false
+			end := i + 1
+			if ch == '\'' || ch == '"' || ch == '`' {
+				if end = strings.IndexByte(sql[i+1:], ch); end < 0 {
+					end = len(sql)
+				} else {
+					end += i + 2
+				}
+			}
+			builder.WriteString(sql[i:end])
+			i = end - 1
+		}
+	}
+	return builder.String()
+}
+
+// Sort the migrations by version, and check whether the versions are valid.
+func sortMigrations(migrations []Migration) ([]Migration, error) {
+	res := append(make([]Migration, 0, len(migrations)), migrations...)
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Version < res[j].Version
+	})
+	for i := range res {
+		if res[i].Version <= 0 {
+			return nil, fmt.Errorf("migration %q: version must be positive, but got %d", res[i].Name, res[i].Version)
+		}
+		if i > 0 && res[i].Version == res[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", res[i].Version)
+		}
+	}
+	return res, nil
+}
+
+/*
+Load migrations from SQL files in the directory of fsys, sub directories are ignored.
+The file names are <version>_<name>.up.sql and <version>_<name>.down.sql, such as 0001_create_flows.up.sql.
+The down file is optional, and the statements in a file are separated by semicolons.
+*/
+func LoadFS(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	migrations := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		version, name, up, ok := parseFileName(entry.Name())
+		if !ok {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m, exist := migrations[version]
+		if !exist {
+			m = &Migration{Version: version, Name: name}
+			migrations[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has different names %q and %q", version, m.Name, name)
+		}
+		statements := SplitStatements(string(content))
+		clauses := make([]sqlbuilder.Clause, 0, len(statements))
+		for _, statement := range statements {
+			clauses = append(clauses, sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, statement))
+		}
+		if up {
+			m.Up = clauses
+		} else {
+			m.Down = clauses
+		}
+	}
+	res := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		if len(m.Up) == 0 {
+			return nil, fmt.Errorf("migration %d has no up statements", m.Version)
+		}
+		res = append(res, *m)
+	}
+	return sortMigrations(res)
+}
+
+// Parse file names such as 0001_create_flows.up.sql.
+func parseFileName(fileName string) (int64, string, bool, bool) {
+	var up bool
+	switch {
+	case strings.HasSuffix(fileName, ".up.sql"):
+		up = true
+		fileName = strings.TrimSuffix(fileName, ".up.sql")
+	case strings.HasSuffix(fileName, ".down.sql"):
+		fileName = strings.TrimSuffix(fileName, ".down.sql")
+	default:
+		return 0, "", false, false
+	}
+	prefix, name, _ := strings.Cut(fileName, "_")
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return 0, "", false, false
+	}
+	return version, name, up, true
+}
+
+// Split the SQL script into statements by semicolons, semicolons in quotes, dollar quotes and comments are ignored.
+// The statements are trimmed, and empty ones are dropped.
+func SplitStatements(script string) []string {
+	var (
+		statements []string
+		begin      int
+	)
+	appendStatement := func(statement string) {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	for i := 0; i < len(script); i++ {
+		switch ch := script[i]; {
+		case ch == '\'' || ch == '"' || ch == '`':
+			for i++; i < len(script) && script[i] != ch; i++ {
+			}
+		case ch == '-' && strings.HasPrefix(script[i:], "--"):
+			for ; i < len(script) && script[i] != '\n'; i++ {
+			}
+		case ch == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 3
+			}
+		case ch == '$' && (i == 0 || !isTagPart(script[i-1])):
+			tag := dollarTag(script[i:])
+			if tag == "" {
+				continue
+			}
+			end := strings.Index(script[i+len(tag):], tag)
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += len(tag) + end + len(tag) - 1
+			}
+		case ch == ';':
+			appendStatement(script[begin:i])
+			begin = i + 1
+		}
+	}
+	if begin < len(script) {
+		appendStatement(script[begin:])
+	}
+	return statements
+}
+
+// Get the dollar quote of PostgreSQL at the beginning of str, such as "$$" and "$body$".
+// It's empty if str does not begin with a dollar quote, such as the placeholder "$1".
+func dollarTag(str string) string {
+	for i := 1; i < len(str); i++ {
+		switch ch := str[i]; {
+		case ch == '$':
+			return str[:i+1]
+		case !isTagPart(ch) || (i == 1 && ch >= '0' && ch <= '9'):
+			return ""
+		}
+	}
+	return ""
+}
+
+func isTagPart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
@@ -0,0 +1,103 @@
+package migration_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/migration"
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestSplitStatements(t *testing.T) {
+	RegisterTestingT(t)
+	script := "CREATE TABLE a (x TEXT DEFAULT ';');\n-- comment;\nINSERT INTO a VALUES ('a;b') /* ; */;\n\n;  SELECT 1"
+	Expect(migration.SplitStatements(script)).Should(Equal([]string{
+		"CREATE TABLE a (x TEXT DEFAULT ';')",
+		"-- comment;\nINSERT INTO a VALUES ('a;b') /* ; */",
+		"SELECT 1",
+	}))
+	script = "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;\n" +
+		"CREATE FUNCTION g() RETURNS int AS $body$ SELECT '$$'; $body$ LANGUAGE sql;\n" +
+		"SELECT $1, a$b FROM t; SELECT 2"
+	Expect(migration.SplitStatements(script)).Should(Equal([]string{
+		"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql",
+		"CREATE FUNCTION g() RETURNS int AS $body$ SELECT '$$'; $body$ LANGUAGE sql",
+		"SELECT $1, a$b FROM t",
+		"SELECT 2",
+	}))
+}
+
+func TestLoadFS(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		RegisterTestingT(t)
+		fsys := fstest.MapFS{
+			"migrations/0002_add_mtu.up.sql":        {Data: []byte("ALTER TABLE flows ADD COLUMN mtu INT;")},
+			"migrations/0001_create_flows.up.sql":   {Data: []byte("CREATE TABLE flows (id INT);\nCREATE INDEX i ON flows (id);")},
+			"migrations/0001_create_flows.down.sql": {Data: []byte("DROP TABLE flows;")},
+			"migrations/README.md":                  {Data: []byte("ignored")},
+		}
+		migrations, err := migration.LoadFS(fsys, "migrations")
+		Expect(err).Should(Succeed())
+		Expect(migrations).Should(HaveLen(2))
+		Expect(migrations[0].Version).Should(BeEquivalentTo(1))
+		Expect(migrations[0].Name).Should(Equal("create_flows"))
+		Expect(migrations[0].Up).Should(HaveLen(2))
+		Expect(migrations[0].Down).Should(HaveLen(1))
+		Expect(migrations[1].Version).Should(BeEquivalentTo(2))
+		Expect(migrations[1].Down).Should(BeEmpty())
+	})
+	t.Run("without up", func(t *testing.T) {
+		RegisterTestingT(t)
+		fsys := fstest.MapFS{
+			"0001_create_flows.down.sql": {Data: []byte("DROP TABLE flows;")},
+		}
+		_, err := migration.LoadFS(fsys, ".")
+		Expect(err).ShouldNot(Succeed())
+	})
+	t.Run("different names", func(t *testing.T) {
+		RegisterTestingT(t)
+		fsys := fstest.MapFS{
+			"0001_create_flows.up.sql":   {Data: []byte("CREATE TABLE flows (id INT);")},
+			"0001_create_hosts.down.sql": {Data: []byte("DROP TABLE hosts;")},
+		}
+		_, err := migration.LoadFS(fsys, ".")
+		Expect(err).ShouldNot(Succeed())
+	})
+}
+
+func TestChecksum(t *testing.T) {
+	RegisterTestingT(t)
+	m := migration.Migration{
+		Version: 1,
+		Up:      []sqlbuilder.Clause{sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "CREATE TABLE a (x INT)")},
+	}
+	sum1, err := m.Checksum(sqlbuilder.SQLite)
+	Expect(err).Should(Succeed())
+	Expect(sum1).Should(HaveLen(64))
+	m.Up = append(m.Up, sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "INSERT INTO a VALUES (?)", 1))
+	sum2, err := m.Checksum(sqlbuilder.SQLite)
+	Expect(err).Should(Succeed())
+	Expect(sum2).ShouldNot(Equal(sum1))
+
+	// Whitespaces are normalized, but the types of arguments are kept.
+	m.Up = []sqlbuilder.Clause{
+		sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "CREATE TABLE a (\n  x INT\n)"),
+		sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "INSERT INTO a VALUES ( ? )", 1),
+	}
+	sum3, err := m.Checksum(sqlbuilder.SQLite)
+	Expect(err).Should(Succeed())
+	Expect(sum3).Should(Equal(sum2))
+	m.Up[1] = sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "INSERT INTO a VALUES (?)", "1")
+	sum4, err := m.Checksum(sqlbuilder.SQLite)
+	Expect(err).Should(Succeed())
+	Expect(sum4).ShouldNot(Equal(sum2))
+	m.Up[1] = sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "INSERT INTO a VALUES ('a  b')")
+	sum5, err := m.Checksum(sqlbuilder.SQLite)
+	Expect(err).Should(Succeed())
+	m.Up[1] = sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "INSERT INTO a VALUES ('a b')")
+	sum6, err := m.Checksum(sqlbuilder.SQLite)
+	Expect(err).Should(Succeed())
+	Expect(sum6).ShouldNot(Equal(sum5))
+}
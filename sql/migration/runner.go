@@ -0,0 +1,363 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+// The default bookkeeping table of migrations.
+const DefaultTable = "schema_migrations"
+
+// Runner applies and reverts migrations, the applied migrations are recorded in the bookkeeping table.
+type Runner struct {
+	DB      *sql.DB
+	Dialect sqlbuilder.Dialect
+	// The bookkeeping table, DefaultTable is used if it's empty.
+	Table      string
+	Migrations []Migration
+	// The statements are written into DryRun in Format mode instead of being executed if DryRun is not nil.
+	// Nothing is written into the DB in dry run, the applied migrations are read from the bookkeeping table
+	// if the DB is not nil and the table exists, otherwise all migrations are treated as pending.
+	DryRun io.Writer
+}
+
+// Record of an applied migration in the bookkeeping table.
+type Record struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+func NewRunner(db *sql.DB, dialect sqlbuilder.Dialect, migrations ...Migration) *Runner {
+	return &Runner{
+		DB:         db,
+		Dialect:    dialect,
+		Migrations: migrations,
+	}
+}
+
+func (r *Runner) table() string {
+	if r.Table == "" {
+		return DefaultTable
+	}
+	return r.Table
+}
+
+// Apply all pending migrations.
+func (r *Runner) Up(ctx context.Context) error {
+	return r.UpTo(ctx, 0)
+}
+
+// Apply pending migrations whose versions are not greater than the version, 0 means all.
+func (r *Runner) UpTo(ctx context.Context, version int64) error {
+	migrations, applied, err := r.load(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range migrations {
+		m := &migrations[i]
+		if version > 0 && m.Version > version {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err = r.up(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Revert the last applied migration.
+func (r *Runner) Down(ctx context.Context) error {
+	migrations, applied, err := r.load(ctx)
+	if err != nil {
+		return err
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if _, ok := applied[migrations[i].Version]; ok {
+			return r.down(ctx, &migrations[i])
+		}
+	}
+	return nil
+}
+
+// Revert applied migrations whose versions are greater than the version.
+func (r *Runner) DownTo(ctx context.Context, version int64) error {
+	migrations, applied, err := r.load(ctx)
+	if err != nil {
+		return err
+	}
+	for i := len(migrations) - 1; i >= 0 && migrations[i].Version > version; i-- {
+		if _, ok := applied[migrations[i].Version]; !ok {
+			continue
+		}
+		if err = r.down(ctx, &migrations[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify the applied migrations, it fails if an applied migration is missing or edited.
+func (r *Runner) Verify(ctx context.Context) error {
+	_, _, err := r.load(ctx)
+	return err
+}
+
+// Get the records of applied migrations in the ascending order of versions.
+// The bookkeeping table is created if it does not exist, except in dry run.
+func (r *Runner) Applied(ctx context.Context) ([]Record, error) {
+	if r.DryRun == nil {
+		if err := r.ensureTable(ctx); err != nil {
+			return nil, err
+		}
+	} else if exist, err := r.tableExists(ctx); err != nil || !exist {
+		return nil, err
+	}
+	l := sqlbuilder.DQL{
+		Select: sqlbuilder.Select{Columns: []string{"version", "name", "checksum", "applied_at"}},
+		From:   sqlbuilder.FromTableName(r.table()),
+		Order:  sqlbuilder.MakeOrderby("version"),
+	}
+	query, args, err := sqlbuilder.Build(&l, r.Dialect, sqlbuilder.Compact)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.DB.QueryContext(ctx, query, toAny(args)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var records []Record
+	for rows.Next() {
+		var (
+			record    Record
+			appliedAt int64
+		)
+		if err = rows.Scan(&record.Version, &record.Name, &record.Checksum, &appliedAt); err != nil {
+			return nil, err
+		}
+		record.AppliedAt = time.Unix(appliedAt, 0)
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// Sort the migrations, and get the applied migrations after verifying them.
+func (r *Runner) load(ctx context.Context) ([]Migration, map[int64]Record, error) {
+	migrations, err := sortMigrations(r.Migrations)
+	if err != nil {
+		return nil, nil, err
+	}
+	applied := make(map[int64]Record)
+	if r.DB == nil && r.DryRun != nil {
+		return migrations, applied, nil
+	}
+	records, err := r.Applied(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	known := make(map[int64]bool, len(migrations))
+	for i := range migrations {
+		known[migrations[i].Version] = true
+		record, ok := applied[migrations[i].Version]
+		if !ok {
+			continue
+		}
+		checksum, err := migrations[i].Checksum(r.Dialect)
+		if err != nil {
+			return nil, nil, err
+		}
+		if checksum != record.Checksum {
+			return nil, nil, fmt.Errorf("migration %d(%s) has been edited after applied", record.Version, record.Name)
+		}
+	}
+	for _, record := range records {
+		if !known[record.Version] {
+			return nil, nil, fmt.Errorf("applied migration %d(%s) is missing", record.Version, record.Name)
+		}
+	}
+	return migrations, applied, nil
+}
+
+func (r *Runner) ensureTable(ctx context.Context) error {
+	c := sqlbuilder.CreateTable{
+		Name:        r.table(),
+		IfNotExists: true,
+		Columns: []sqlbuilder.ColumnDef{
+			{Name: "version", Type: "BIGINT", NotNull: true, PrimaryKey: true},
+			{Name: "name", Type: "VARCHAR(255)", NotNull: true},
+			{Name: "checksum", Type: "VARCHAR(64)", NotNull: true},
+			{Name: "applied_at", Type: "BIGINT", NotNull: true},
+		},
+	}
+	query, _, err := sqlbuilder.Build(&c, r.Dialect, sqlbuilder.Compact)
+	if err != nil {
+		return err
+	}
+	_, err = r.DB.ExecContext(ctx, query)
+	return err
+}
+
+// Check whether the bookkeeping table exists without creating it.
+func (r *Runner) tableExists(ctx context.Context) (bool, error) {
+	l := sqlbuilder.DQL{
+		Select: sqlbuilder.Select{Columns: []string{"COUNT(*)"}},
+		From:   sqlbuilder.FromTableName("information_schema.tables"),
+	}
+	switch r.Dialect {
+	case sqlbuilder.SQLite:
+		l.From = sqlbuilder.FromTableName("sqlite_master")
+		l.Where.Conditions = []sqlbuilder.Condition{sqlbuilder.NewCondition("type = 'table' AND name = ?", r.table())}
+	case sqlbuilder.PostgreSQL:
+		l.Where.Conditions = []sqlbuilder.Condition{sqlbuilder.NewCondition("table_schema = current_schema() AND table_name = ?", r.table())}
+	case sqlbuilder.MySQL:
+		l.Where.Conditions = []sqlbuilder.Condition{sqlbuilder.NewCondition("table_schema = DATABASE() AND table_name = ?", r.table())}
+	default:
+		l.Where.Conditions = []sqlbuilder.Condition{sqlbuilder.NewCondition("table_name = ?", r.table())}
+	}
+	query, args, err := sqlbuilder.Build(&l, r.Dialect, sqlbuilder.Compact)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	if err = r.DB.QueryRowContext(ctx, query, toAny(args)...).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *Runner) up(ctx context.Context, m *Migration) error {
+	checksum, err := m.Checksum(r.Dialect)
+	if err != nil {
+		return err
+	}
+	record := sqlbuilder.NewNamedClause(sqlbuilder.AutoNewline,
+		"INSERT INTO "+r.table()+" (version, name, checksum, applied_at) VALUES (:version, :name, :checksum, :applied_at)",
+		map[string]any{"version": m.Version, "name": m.Name, "checksum": checksum, "applied_at": time.Now().Unix()},
+	)
+	statements := append(append(make([]sqlbuilder.Clause, 0, len(m.Up)+1), m.Up...), record)
+	if err = r.exec(ctx, m, "up", statements, transactional(m, m.Up)); err != nil {
+		return fmt.Errorf("apply migration %d(%s): %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+func (r *Runner) down(ctx context.Context, m *Migration) error {
+	if len(m.Down) == 0 {
+		return fmt.Errorf("migration %d(%s) is irreversible", m.Version, m.Name)
+	}
+	record := &sqlbuilder.Delete{
+		Table: r.table(),
+		Where: sqlbuilder.WhereClause{
+			Conditions: []sqlbuilder.Condition{
+				sqlbuilder.NewNamedCondition("version = :version", map[string]any{"version": m.Version}),
+			},
+		},
+	}
+	statements := append(append(make([]sqlbuilder.Clause, 0, len(m.Down)+1), m.Down...), record)
+	if err := r.exec(ctx, m, "down", statements, transactional(m, m.Down)); err != nil {
+		return fmt.Errorf("revert migration %d(%s): %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+// Whether the statements of the migration can be executed in a transaction.
+// Indexes built or dropped concurrently can not, because PostgreSQL rejects them in transactions.
+func transactional(m *Migration, statements []sqlbuilder.Clause) bool {
+	if m.NoTransaction {
+		return false
+	}
+	for _, c := range statements {
+		switch c := c.(type) {
+		case *sqlbuilder.CreateIndex:
+			if c.Concurrently {
+				return false
+			}
+		case *sqlbuilder.DropIndex:
+			if c.Concurrently {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Execute the statements in a transaction if transaction is true and the dialect supports transactional DDL.
+// Without the transaction, the statements executed before a failure are not reverted.
+func (r *Runner) exec(ctx context.Context, m *Migration, direction string, statements []sqlbuilder.Clause, transaction bool) error {
+	if r.DryRun != nil {
+		return r.print(m, direction, statements)
+	}
+	var (
+		tx   *sql.Tx
+		exec func(ctx context.Context, query string, args ...any) (sql.Result, error)
+		err  error
+	)
+	// DDL causes an implicit commit in MySQL, so the transaction is useless.
+	if !transaction || r.Dialect == sqlbuilder.MySQL {
+		exec = r.DB.ExecContext
+	} else {
+		if tx, err = r.DB.BeginTx(ctx, nil); err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback() }()
+		exec = tx.ExecContext
+	}
+	for _, c := range statements {
+		query, args, err := sqlbuilder.Build(c, r.Dialect, sqlbuilder.Compact)
+		if err != nil {
+			return err
+		}
+		if _, err = exec(ctx, query, toAny(args)...); err != nil {
+			return err
+		}
+	}
+	if tx != nil {
+		return tx.Commit()
+	}
+	return nil
+}
+
+func (r *Runner) print(m *Migration, direction string, statements []sqlbuilder.Clause) error {
+	if _, err := fmt.Fprintf(r.DryRun, "-- %d %s %s\n", m.Version, m.Name, direction); err != nil {
+		return err
+	}
+	for _, c := range statements {
+		query, args, err := sqlbuilder.Build(c, r.Dialect, sqlbuilder.Format)
+		if err != nil {
+			return err
+		}
+		if len(args) != 0 {
+			_, err = fmt.Fprintf(r.DryRun, "%s-- args: %v\n", query, args)
+		} else {
+			_, err = fmt.Fprint(r.DryRun, query)
+		}
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintln(r.DryRun, ";"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toAny(args []sqlbuilder.Arg) []any {
+	res := make([]any, len(args))
+	for i, arg := range args {
+		res[i] = arg
+	}
+	return res
+}
@@ -0,0 +1,195 @@
+package migration_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	_ "modernc.org/sqlite"
+
+	"github.com/everoute/util/sql/migration"
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newMigrations() []migration.Migration {
+	return []migration.Migration{
+		{
+			Version: 2,
+			Name:    "add_mtu",
+			Up: []sqlbuilder.Clause{
+				&sqlbuilder.AlterTable{Name: "flows", Actions: []sqlbuilder.AlterAction{
+					&sqlbuilder.AddColumn{Column: sqlbuilder.ColumnDef{Name: "mtu", Type: "INTEGER", Default: "1500"}},
+				}},
+			},
+			Down: []sqlbuilder.Clause{
+				&sqlbuilder.AlterTable{Name: "flows", Actions: []sqlbuilder.AlterAction{&sqlbuilder.DropColumn{Name: "mtu"}}},
+			},
+		},
+		{
+			Version: 1,
+			Name:    "create_flows",
+			Up: []sqlbuilder.Clause{
+				&sqlbuilder.CreateTable{Name: "flows", Columns: []sqlbuilder.ColumnDef{
+					{Name: "id", Type: "INTEGER", PrimaryKey: true},
+					{Name: "name", Type: "TEXT", NotNull: true},
+				}},
+				&sqlbuilder.CreateIndex{Name: "idx_flows_name", Table: "flows", Columns: []string{"name"}},
+			},
+			Down: []sqlbuilder.Clause{
+				&sqlbuilder.DropTable{Name: "flows"},
+			},
+		},
+	}
+}
+
+func columnsOf(db *sql.DB, table string) []string {
+	rows, err := db.Query("SELECT name FROM pragma_table_info(?) ORDER BY cid", table)
+	Expect(err).Should(Succeed())
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var name string
+		Expect(rows.Scan(&name)).Should(Succeed())
+		columns = append(columns, name)
+	}
+	Expect(rows.Err()).Should(Succeed())
+	return columns
+}
+
+func TestRunner(t *testing.T) {
+	ctx := context.Background()
+	t.Run("up and down", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := openSQLite(t)
+		r := migration.NewRunner(db, sqlbuilder.SQLite, newMigrations()...)
+		Expect(r.UpTo(ctx, 1)).Should(Succeed())
+		Expect(columnsOf(db, "flows")).Should(Equal([]string{"id", "name"}))
+		Expect(r.Up(ctx)).Should(Succeed())
+		Expect(columnsOf(db, "flows")).Should(Equal([]string{"id", "name", "mtu"}))
+		records, err := r.Applied(ctx)
+		Expect(err).Should(Succeed())
+		Expect(records).Should(HaveLen(2))
+		Expect(records[0].Name).Should(Equal("create_flows"))
+		Expect(records[1].Version).Should(BeEquivalentTo(2))
+
+		Expect(r.Down(ctx)).Should(Succeed())
+		Expect(columnsOf(db, "flows")).Should(Equal([]string{"id", "name"}))
+		Expect(r.DownTo(ctx, 0)).Should(Succeed())
+		Expect(columnsOf(db, "flows")).Should(BeEmpty())
+		records, err = r.Applied(ctx)
+		Expect(err).Should(Succeed())
+		Expect(records).Should(BeEmpty())
+	})
+	t.Run("rollback on failure", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := openSQLite(t)
+		migrations := newMigrations()
+		migrations[1].Up = append(migrations[1].Up, sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "INVALID SQL"))
+		r := migration.NewRunner(db, sqlbuilder.SQLite, migrations...)
+		Expect(r.Up(ctx)).ShouldNot(Succeed())
+		Expect(columnsOf(db, "flows")).Should(BeEmpty())
+		records, err := r.Applied(ctx)
+		Expect(err).Should(Succeed())
+		Expect(records).Should(BeEmpty())
+	})
+	t.Run("no transaction", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := openSQLite(t)
+		// VACUUM can not be executed in a transaction.
+		vacuum := migration.Migration{Version: 3, Name: "vacuum", Up: []sqlbuilder.Clause{sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "VACUUM")}}
+		migrations := append(newMigrations(), vacuum)
+		Expect(migration.NewRunner(db, sqlbuilder.SQLite, migrations...).Up(ctx)).ShouldNot(Succeed())
+		migrations[2].NoTransaction = true
+		Expect(migration.NewRunner(db, sqlbuilder.SQLite, migrations...).Up(ctx)).Should(Succeed())
+		records, err := migration.NewRunner(db, sqlbuilder.SQLite).Applied(ctx)
+		Expect(err).Should(Succeed())
+		Expect(records).Should(HaveLen(3))
+	})
+	t.Run("concurrent index without transaction", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := openSQLite(t)
+		m := migration.Migration{Version: 1, Name: "create_flows", Up: []sqlbuilder.Clause{
+			&sqlbuilder.CreateTable{Name: "flows", Columns: []sqlbuilder.ColumnDef{{Name: "id", Type: "INTEGER"}}},
+			&sqlbuilder.CreateIndex{Name: "idx_flows_id", Table: "flows", Columns: []string{"id"}, Concurrently: true},
+		}}
+		// CONCURRENTLY is rendered for Generic but rejected by SQLite, the table created before is kept without the transaction.
+		Expect(migration.NewRunner(db, sqlbuilder.Generic, m).Up(ctx)).Should(MatchError(ContainSubstring("syntax error")))
+		Expect(columnsOf(db, "flows")).Should(Equal([]string{"id"}))
+	})
+	t.Run("edited migration", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := openSQLite(t)
+		Expect(migration.NewRunner(db, sqlbuilder.SQLite, newMigrations()...).Up(ctx)).Should(Succeed())
+		migrations := newMigrations()
+		migrations[1].Up[1] = &sqlbuilder.CreateIndex{Name: "idx_flows_id", Table: "flows", Columns: []string{"id"}}
+		err := migration.NewRunner(db, sqlbuilder.SQLite, migrations...).Verify(ctx)
+		Expect(err).Should(MatchError(ContainSubstring("edited")))
+	})
+	t.Run("missing migration", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := openSQLite(t)
+		Expect(migration.NewRunner(db, sqlbuilder.SQLite, newMigrations()...).Up(ctx)).Should(Succeed())
+		err := migration.NewRunner(db, sqlbuilder.SQLite, newMigrations()[1]).Up(ctx)
+		Expect(err).Should(MatchError(ContainSubstring("missing")))
+	})
+	t.Run("irreversible", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := openSQLite(t)
+		migrations := newMigrations()
+		migrations[0].Down = nil
+		r := migration.NewRunner(db, sqlbuilder.SQLite, migrations...)
+		Expect(r.Up(ctx)).Should(Succeed())
+		Expect(r.Down(ctx)).Should(MatchError(ContainSubstring("irreversible")))
+	})
+	t.Run("duplicate version", func(t *testing.T) {
+		RegisterTestingT(t)
+		migrations := newMigrations()
+		migrations[0].Version = 1
+		err := migration.NewRunner(openSQLite(t), sqlbuilder.SQLite, migrations...).Up(ctx)
+		Expect(err).ShouldNot(Succeed())
+	})
+	t.Run("dry run", func(t *testing.T) {
+		RegisterTestingT(t)
+		var buff bytes.Buffer
+		r := migration.NewRunner(nil, sqlbuilder.SQLite, newMigrations()...)
+		r.DryRun = &buff
+		Expect(r.Up(ctx)).Should(Succeed())
+		Expect(buff.String()).Should(HavePrefix("-- 1 create_flows up\nCREATE TABLE flows (\n  id INTEGER PRIMARY KEY,\n  name TEXT NOT NULL\n)\n;\n" +
+			"CREATE INDEX idx_flows_name ON flows (name)\n;\n" +
+			"INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)\n-- args: [1 create_flows "))
+		Expect(buff.String()).Should(ContainSubstring("-- 2 add_mtu up\nALTER TABLE flows\n  ADD COLUMN mtu INTEGER DEFAULT 1500\n;\n"))
+	})
+	t.Run("dry run with database", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := openSQLite(t)
+		Expect(migration.NewRunner(db, sqlbuilder.SQLite, newMigrations()...).UpTo(ctx, 1)).Should(Succeed())
+		var buff bytes.Buffer
+		r := migration.NewRunner(db, sqlbuilder.SQLite, newMigrations()...)
+		r.DryRun = &buff
+		Expect(r.Up(ctx)).Should(Succeed())
+		Expect(buff.String()).Should(HavePrefix("-- 2 add_mtu up\n"))
+		Expect(columnsOf(db, "flows")).Should(Equal([]string{"id", "name"}))
+	})
+	t.Run("dry run writes nothing", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := openSQLite(t)
+		var buff bytes.Buffer
+		r := migration.NewRunner(db, sqlbuilder.SQLite, newMigrations()...)
+		r.DryRun = &buff
+		Expect(r.Up(ctx)).Should(Succeed())
+		Expect(buff.String()).Should(HavePrefix("-- 1 create_flows up\n"))
+		Expect(columnsOf(db, migration.DefaultTable)).Should(BeEmpty())
+	})
+}
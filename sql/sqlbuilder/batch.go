@@ -0,0 +1,193 @@
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Get the maximum number of bound parameters in a statement.
+// The limit of SQLite is 32766 since 3.32.0, set BatchInsert.MaxParams to 999 for older versions.
+func (d Dialect) MaxParams() int {
+	switch d {
+	case MySQL, PostgreSQL:
+		return 65535
+	case SQLite:
+		return 32766
+	default:
+		return 999
+	}
+}
+
+/*
+RowIterator iterates the rows to insert, Next returns io.EOF after the last row.
+The rows returned by Next are buffered until the statement containing them is executed,
+so Next must return a new slice for each row instead of overwriting the previous one.
+The buffer is reused after each flush, and the rows are no longer referenced by BatchInsert after that.
+*/
+type RowIterator interface {
+	Next() ([]Arg, error)
+}
+
+type RowsFunc func() ([]Arg, error)
+
+func (f RowsFunc) Next() ([]Arg, error) {
+	return f()
+}
+
+// Iterate the rows in the slice.
+func SliceRows(rows [][]Arg) RowIterator {
+	i := 0
+	return RowsFunc(func() ([]Arg, error) {
+		if i >= len(rows) {
+			return nil, io.EOF
+		}
+		i++
+		return rows[i-1], nil
+	})
+}
+
+// Execer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+/*
+BatchInsert splits the rows into multiple INSERT statements, so that each statement
+has no more parameters than MaxParams and no longer SQL than MaxStatementSize.
+The rows are read from the iterator lazily, only the rows of one statement are kept in memory.
+*/
+type BatchInsert struct {
+	Table   string
+	Columns []string
+	Rows    RowIterator
+	// Appended to each statement, such as "ON CONFLICT DO NOTHING".
+	Additional Clauses
+	// The maximum number of parameters in a statement, the limit of the dialect is used if it's 0.
+	MaxParams int
+	// The maximum bytes of SQL in a statement, it's unlimited if it's 0.
+	MaxStatementSize int
+	// Called after each statement is executed with the number of rows inserted so far.
+	Progress func(inserted int64)
+}
+
+// Build the statements in Compact mode, and call fn with each statement and the number of rows in it.
+func (b *BatchInsert) Each(dialect Dialect, fn func(query string, args []Arg, rows int) error) error {
+	if len(b.Columns) == 0 {
+		return errors.New("no columns to insert")
+	}
+	maxParams := b.MaxParams
+	if maxParams <= 0 {
+		maxParams = dialect.MaxParams()
+	}
+	// The statement except the rows, the placeholders of Additional are estimated for the size.
+	insert := Insert{Table: b.Table, Columns: b.Columns, Query: NewSimpleClause(AutoNewline, "VALUES"), Additional: b.Additional}
+	query, args, err := Build(&insert, dialect, Compact)
+	if err != nil {
+		return err
+	}
+	var base int
+	if b.MaxStatementSize > 0 {
+		base = len(query) + len(args)*len(dialect.Placeholder(maxParams))
+	}
+	// The parameters of Additional, such as ON CONFLICT ... SET, are shared by all rows.
+	maxParams -= len(args)
+	var (
+		rows   [][]Arg
+		params int
+		size   = base
+	)
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		insert := Insert{Table: b.Table, Columns: b.Columns, Values: rows, Additional: b.Additional}
+		query, args, err := Build(&insert, dialect, Compact)
+		if err != nil {
+			return err
+		}
+		if err = fn(query, args, len(rows)); err != nil {
+			return err
+		}
+		rows, params, size = rows[:0], 0, base
+		return nil
+	}
+	for i := 0; ; i++ {
+		row, err := b.Rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(row) != len(b.Columns) {
+			return fmt.Errorf("row %d has %d values, but %d columns are expected", i, len(row), len(b.Columns))
+		}
+		rowSize := b.rowSize(dialect, params, len(row))
+		if len(row) > maxParams || base+rowSize > b.MaxStatementSize && b.MaxStatementSize > 0 {
+			return fmt.Errorf("row %d exceeds the limits of a statement", i)
+		}
+		if params+len(row) > maxParams || size+rowSize > b.MaxStatementSize && b.MaxStatementSize > 0 {
+			if err = flush(); err != nil {
+				return err
+			}
+			rowSize = b.rowSize(dialect, params, len(row))
+		}
+		rows = append(rows, row)
+		params += len(row)
+		size += rowSize
+	}
+	return flush()
+}
+
+// The size of the row "($1, $2), " whose first parameter is the (offset+1)-th.
+func (b *BatchInsert) rowSize(dialect Dialect, offset, n int) int {
+	size := len("(), ")
+	for i := 1; i <= n; i++ {
+		size += len(dialect.Placeholder(offset + i))
+		if i != n {
+			size += len(", ")
+		}
+	}
+	return size
+}
+
+// Execute the statements, returns the number of rows inserted.
+func (b *BatchInsert) Exec(ctx context.Context, db Execer, dialect Dialect) (int64, error) {
+	var inserted int64
+	err := b.Each(dialect, func(query string, args []Arg, rows int) error {
+		if _, err := db.ExecContext(ctx, query, toAny(args)...); err != nil {
+			return err
+		}
+		inserted += int64(rows)
+		if b.Progress != nil {
+			b.Progress(inserted)
+		}
+		return nil
+	})
+	return inserted, err
+}
+
+// Execute the statements in a transaction, nothing is inserted if any statement fails.
+func (b *BatchInsert) ExecTx(ctx context.Context, db *sql.DB, dialect Dialect, opts *sql.TxOptions) (int64, error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	inserted, err := b.Exec(ctx, tx, dialect)
+	if err != nil {
+		return 0, err
+	}
+	return inserted, tx.Commit()
+}
+
+func toAny(args []Arg) []any {
+	res := make([]any, len(args))
+	for i, arg := range args {
+		res[i] = arg
+	}
+	return res
+}
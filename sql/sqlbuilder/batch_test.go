@@ -0,0 +1,124 @@
+package sqlbuilder_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	_ "modernc.org/sqlite"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func genRows(n int) [][]sqlbuilder.Arg {
+	rows := make([][]sqlbuilder.Arg, n)
+	for i := range rows {
+		rows[i] = []sqlbuilder.Arg{i, "name"}
+	}
+	return rows
+}
+
+func TestBatchInsert(t *testing.T) {
+	t.Run("max params", func(t *testing.T) {
+		RegisterTestingT(t)
+		b := &sqlbuilder.BatchInsert{Table: "t", Columns: []string{"id", "name"}, Rows: sqlbuilder.SliceRows(genRows(7)), MaxParams: 5}
+		var counts []int
+		Expect(b.Each(sqlbuilder.PostgreSQL, func(query string, args []sqlbuilder.Arg, rows int) error {
+			Expect(args).Should(HaveLen(rows * 2))
+			counts = append(counts, rows)
+			return nil
+		})).Should(Succeed())
+		Expect(counts).Should(Equal([]int{2, 2, 2, 1}))
+		Expect(sqlbuilder.PostgreSQL.MaxParams()).Should(Equal(65535))
+
+		// The parameters of Additional are counted.
+		b.Rows, b.MaxParams = sqlbuilder.SliceRows(genRows(7)), 6
+		b.Additional = sqlbuilder.Clauses{sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "ON CONFLICT (id) DO UPDATE SET name = ?", "x")}
+		counts = nil
+		Expect(b.Each(sqlbuilder.PostgreSQL, func(query string, args []sqlbuilder.Arg, rows int) error {
+			Expect(len(args)).Should(BeNumerically("<=", 6))
+			counts = append(counts, rows)
+			return nil
+		})).Should(Succeed())
+		Expect(counts).Should(Equal([]int{2, 2, 2, 1}))
+		b.Rows, b.MaxParams = sqlbuilder.SliceRows(genRows(1)), 2
+		Expect(b.Each(sqlbuilder.PostgreSQL, func(string, []sqlbuilder.Arg, int) error { return nil })).
+			Should(MatchError("row 0 exceeds the limits of a statement"))
+		Expect(sqlbuilder.SQLite.MaxParams()).Should(Equal(32766))
+	})
+	t.Run("max statement size", func(t *testing.T) {
+		RegisterTestingT(t)
+		b := &sqlbuilder.BatchInsert{
+			Table:            "t",
+			Columns:          []string{"id", "name"},
+			Rows:             sqlbuilder.SliceRows(genRows(100)),
+			Additional:       sqlbuilder.Clauses{sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "ON CONFLICT (id) DO UPDATE SET name = ?", "x")},
+			MaxStatementSize: 200,
+		}
+		var total int
+		Expect(b.Each(sqlbuilder.PostgreSQL, func(query string, args []sqlbuilder.Arg, rows int) error {
+			Expect(len(query)).Should(BeNumerically("<=", 200))
+			Expect(args).Should(HaveLen(rows*2 + 1))
+			total += rows
+			return nil
+		})).Should(Succeed())
+		Expect(total).Should(Equal(100))
+
+		b.Rows, b.MaxStatementSize = sqlbuilder.SliceRows(genRows(1)), 10
+		Expect(b.Each(sqlbuilder.PostgreSQL, func(string, []sqlbuilder.Arg, int) error { return nil })).
+			Should(MatchError("row 0 exceeds the limits of a statement"))
+	})
+	t.Run("iterator error", func(t *testing.T) {
+		RegisterTestingT(t)
+		n := 0
+		b := &sqlbuilder.BatchInsert{Table: "t", Columns: []string{"id"}, Rows: sqlbuilder.RowsFunc(func() ([]sqlbuilder.Arg, error) {
+			if n++; n > 3 {
+				return nil, errors.New("broken")
+			}
+			return []sqlbuilder.Arg{n}, nil
+		})}
+		Expect(b.Each(sqlbuilder.MySQL, func(string, []sqlbuilder.Arg, int) error { return nil })).Should(MatchError("broken"))
+		b.Rows = sqlbuilder.SliceRows([][]sqlbuilder.Arg{{1, 2}})
+		Expect(b.Each(sqlbuilder.MySQL, func(string, []sqlbuilder.Arg, int) error { return nil })).
+			Should(MatchError("row 0 has 2 values, but 1 columns are expected"))
+		b.Rows = sqlbuilder.RowsFunc(func() ([]sqlbuilder.Arg, error) { return nil, io.EOF })
+		Expect(b.Each(sqlbuilder.MySQL, func(string, []sqlbuilder.Arg, int) error {
+			return errors.New("unexpected statement")
+		})).Should(Succeed())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		RegisterTestingT(t)
+		ctx := context.Background()
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+		Expect(err).Should(Succeed())
+		defer db.Close()
+		_, err = db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT)")
+		Expect(err).Should(Succeed())
+
+		var progress []int64
+		b := &sqlbuilder.BatchInsert{
+			Table:     "t",
+			Columns:   []string{"id", "name"},
+			Rows:      sqlbuilder.SliceRows(genRows(2500)),
+			MaxParams: 999,
+			Progress:  func(inserted int64) { progress = append(progress, inserted) },
+		}
+		inserted, err := b.ExecTx(ctx, db, sqlbuilder.SQLite, nil)
+		Expect(err).Should(Succeed())
+		Expect(inserted).Should(Equal(int64(2500)))
+		Expect(progress).Should(Equal([]int64{499, 998, 1497, 1996, 2495, 2500}))
+
+		// Duplicated primary keys, the transaction is rolled back.
+		b.Rows = sqlbuilder.SliceRows(append([][]sqlbuilder.Arg{{5000, "new"}}, genRows(1)...))
+		b.MaxParams = 2
+		_, err = b.ExecTx(ctx, db, sqlbuilder.SQLite, nil)
+		Expect(err).Should(HaveOccurred())
+		var count int
+		Expect(db.QueryRow("SELECT count(*) FROM t").Scan(&count)).Should(Succeed())
+		Expect(count).Should(Equal(2500))
+	})
+}
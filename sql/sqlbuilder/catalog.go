@@ -0,0 +1,317 @@
+package sqlbuilder
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ColumnType is the type of column declared in Catalog, it decides which arguments are acceptable.
+type ColumnType int
+
+const (
+	AnyType    ColumnType = iota // Accept all arguments.
+	IntType                      // Accept signed and unsigned integers.
+	FloatType                    // Accept floats and integers.
+	StringType                   // Accept strings.
+	BoolType                     // Accept booleans.
+	BytesType                    // Accept []byte.
+	TimeType                     // Accept time.Time.
+)
+
+func (t ColumnType) String() string {
+	switch t {
+	case AnyType:
+		return "any"
+	case IntType:
+		return "int"
+	case FloatType:
+		return "float"
+	case StringType:
+		return "string"
+	case BoolType:
+		return "bool"
+	case BytesType:
+		return "bytes"
+	case TimeType:
+		return "time"
+	default:
+		return fmt.Sprintf("type(%d)", int(t))
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Whether the argument is acceptable for the column type, nil pointers and driver.Valuer are always acceptable.
+func (t ColumnType) Accept(arg Arg) bool {
+	if arg == nil || t == AnyType {
+		return true
+	}
+	if _, ok := arg.(driver.Valuer); ok {
+		return true
+	}
+	value := reflect.ValueOf(arg)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return true
+		}
+		value = value.Elem()
+	}
+	switch kind := value.Kind(); t {
+	case IntType:
+		return isIntKind(kind)
+	case FloatType:
+		return isIntKind(kind) || kind == reflect.Float32 || kind == reflect.Float64
+	case StringType:
+		return kind == reflect.String
+	case BoolType:
+		return kind == reflect.Bool
+	case BytesType:
+		return kind == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8
+	case TimeType:
+		return value.Type().ConvertibleTo(timeType)
+	default:
+		return false
+	}
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Uint64
+}
+
+// Catalog declares the tables and columns, the clause trees can be validated against it before execution.
+type Catalog struct {
+	tables map[string]map[string]ColumnType
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{
+		tables: make(map[string]map[string]ColumnType),
+	}
+}
+
+// Declare a table with its columns, the table is replaced if it has been declared.
+func (c *Catalog) AddTable(name string, columns map[string]ColumnType) *Catalog {
+	c.tables[name] = columns
+	return c
+}
+
+// Get the type of the column in the table.
+func (c *Catalog) Column(table, column string) (ColumnType, bool) {
+	columns, ok := c.tables[table]
+	if !ok {
+		return AnyType, false
+	}
+	t, ok := columns[column]
+	return t, ok
+}
+
+var (
+	columnRefPattern = regexp.MustCompile(`^(?:([A-Za-z_][A-Za-z0-9_]*)\.)?([A-Za-z_][A-Za-z0-9_]*|\*)$`)
+	// The table with an optional alias, such as "flows", "flows f" or "flows AS f".
+	tableAliasPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)(?:\s+(?:[Aa][Ss]\s+)?([A-Za-z_][A-Za-z0-9_]*))?$`)
+)
+
+// The typed columns referenced in expressions, such as Column[T].
+type columnRef interface {
+	columnRef() (table, name string)
+}
+
+/*
+Validate the clause tree against the catalog, all problems are returned at once by errors.Join.
+The typed nodes are validated: tables in FROM, JOIN, UPDATE and DELETE, columns of ColumnCondition and Column,
+and columns of SELECT which are plain names such as "id", "flows.id" or "flows.*".
+The ColumnConditions and Columns in Select.Expressions, GROUP BY and ORDER BY expressions are validated as well.
+It reports unknown tables and columns, ambiguous unqualified columns across joined tables,
+and arguments of ColumnCondition which are not acceptable for the column type.
+The tables can be aliased such as "flows AS f", the columns qualified by the alias are validated against the table.
+Common table expressions and sub queries with names are in the scope, but their columns are not validated.
+The sub queries in expressions are validated in their own scopes, so the columns of outer tables can not be referenced
+by ColumnCondition in them.
+*/
+func (c *Catalog) Validate(root Clause) error {
+	var errs []error
+	ctes := make(map[string]bool)
+	Inspect(root, func(node Node) bool {
+		if with, ok := node.(*WithClause); ok {
+			for _, t := range with.Tables {
+				ctes[t.Name] = true
+			}
+		}
+		return true
+	})
+	Inspect(root, func(node Node) bool {
+		switch n := node.(type) {
+		case *DQL:
+			s := c.newScope(ctes, &errs, n.From.Table)
+			for i := range n.Joins {
+				s.addTable(n.Joins[i].Table)
+			}
+			for _, col := range n.Select.Columns {
+				if match := columnRefPattern.FindStringSubmatch(col); match != nil {
+					s.resolve(match[1], match[2])
+				}
+			}
+			for _, e := range n.Select.Expressions {
+				s.inspect(e)
+			}
+			for i := range n.Joins {
+				s.conditions(n.Joins[i].On)
+			}
+			s.conditions(n.Where.Conditions)
+			s.inspect(n.Group)
+			s.conditions(n.Having.Conditions)
+			s.inspect(n.Order)
+		case *Update:
+			c.newScope(ctes, &errs, TableByName(n.Table)).conditions(n.Where.Conditions)
+		case *Delete:
+			c.newScope(ctes, &errs, TableByName(n.Table)).conditions(n.Where.Conditions)
+		}
+		return true
+	})
+	return errors.Join(errs...)
+}
+
+// The tables visible for the columns in a statement.
+type catalogScope struct {
+	catalog *Catalog
+	ctes    map[string]bool
+	errs    *[]error
+	// The names or aliases of tables in the scope, the value is the table in the catalog,
+	// it's empty if the columns are unknown.
+	tables map[string]string
+	order  []string
+}
+
+func (c *Catalog) newScope(ctes map[string]bool, errs *[]error, table Table) *catalogScope {
+	s := &catalogScope{
+		catalog: c,
+		ctes:    ctes,
+		errs:    errs,
+		tables:  make(map[string]string),
+	}
+	s.addTable(table)
+	return s
+}
+
+func (s *catalogScope) addTable(t Table) {
+	if t.Name == "" {
+		return
+	}
+	name, table := t.Name, ""
+	if t.Clause == nil {
+		base := t.Name
+		if match := tableAliasPattern.FindStringSubmatch(t.Name); match != nil {
+			base, name = match[1], match[1]
+			if match[2] != "" {
+				name = match[2]
+			}
+		}
+		if !s.ctes[base] {
+			if _, ok := s.catalog.tables[base]; ok {
+				table = base
+			} else {
+				*s.errs = append(*s.errs, fmt.Errorf("unknown table %q", base))
+			}
+		}
+	}
+	if _, ok := s.tables[name]; !ok {
+		s.order = append(s.order, name)
+	}
+	s.tables[name] = table
+}
+
+// Resolve the column, returns the type of the column if it's declared in the catalog.
+func (s *catalogScope) resolve(table, column string) (ColumnType, bool) {
+	if table != "" {
+		base, ok := s.tables[table]
+		if !ok {
+			*s.errs = append(*s.errs, fmt.Errorf("table %q of column %q is not in the scope", table, column))
+			return AnyType, false
+		}
+		if base == "" || column == "*" {
+			return AnyType, false
+		}
+		t, ok := s.catalog.Column(base, column)
+		if !ok {
+			*s.errs = append(*s.errs, fmt.Errorf("unknown column %q in table %q", column, table))
+		}
+		return t, ok
+	}
+	if column == "*" {
+		return AnyType, false
+	}
+	var (
+		found    []string
+		allKnown = true
+		res      ColumnType
+	)
+	for _, name := range s.order {
+		base := s.tables[name]
+		if base == "" {
+			allKnown = false
+			continue
+		}
+		if t, ok := s.catalog.Column(base, column); ok {
+			found = append(found, name)
+			res = t
+		}
+	}
+	switch {
+	case len(found) > 1:
+		sort.Strings(found)
+		*s.errs = append(*s.errs, fmt.Errorf("ambiguous column %q in tables %s", column, strings.Join(found, ", ")))
+	case len(found) == 1:
+		return res, true
+	case allKnown && len(s.order) > 0:
+		*s.errs = append(*s.errs, fmt.Errorf("unknown column %q", column))
+	}
+	return AnyType, false
+}
+
+// Validate the ColumnConditions in the conditions, including nested ones.
+func (s *catalogScope) conditions(conditions []Condition) {
+	for _, condition := range conditions {
+		s.inspect(condition)
+	}
+}
+
+// Validate the ColumnConditions and Columns in the node, the sub queries are validated in their own scopes.
+func (s *catalogScope) inspect(node Node) {
+	if node == nil {
+		return
+	}
+	Inspect(node, func(node Node) bool {
+		switch n := node.(type) {
+		case SubQuery, ExistsCondition:
+			return false
+		case columnRef:
+			table, name := n.columnRef()
+			s.resolve(table, name)
+		case ColumnCondition:
+			s.column(n)
+		}
+		return true
+	})
+}
+
+func (s *catalogScope) column(c ColumnCondition) {
+	match := columnRefPattern.FindStringSubmatch(c.Column)
+	if match == nil {
+		return
+	}
+	t, ok := s.resolve(match[1], match[2])
+	if !ok {
+		return
+	}
+	for i, arg := range c.Args {
+		if !t.Accept(arg) {
+			*s.errs = append(*s.errs, fmt.Errorf("argument %d of column %q is %T, but the column is %s", i, c.Column, arg, t))
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/expr"
+)
+
+func newCatalog() *sqlbuilder.Catalog {
+	return sqlbuilder.NewCatalog().
+		AddTable("flows", map[string]sqlbuilder.ColumnType{
+			"id":      sqlbuilder.IntType,
+			"name":    sqlbuilder.StringType,
+			"host_id": sqlbuilder.IntType,
+			"ts":      sqlbuilder.TimeType,
+		}).
+		AddTable("hosts", map[string]sqlbuilder.ColumnType{
+			"id":   sqlbuilder.IntType,
+			"name": sqlbuilder.StringType,
+			"up":   sqlbuilder.BoolType,
+		})
+}
+
+func TestCatalog(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		RegisterTestingT(t)
+		dql := &sqlbuilder.DQL{
+			With: &sqlbuilder.WithClause{Tables: []sqlbuilder.Table{
+				sqlbuilder.NameAsTable("recent", &sqlbuilder.DQL{
+					From:  sqlbuilder.FromTableName("flows"),
+					Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewColumnCondition("ts", ">", time.Now())}},
+				}),
+			}},
+			Select: sqlbuilder.Select{Columns: []string{"flows.id", "hosts.*", "up", "count(*) AS c", "recent.x"}},
+			From:   sqlbuilder.FromTableName("flows"),
+			Joins: []sqlbuilder.Join{
+				{Kind: "JOIN", Table: sqlbuilder.TableByName("hosts"), On: []sqlbuilder.Condition{sqlbuilder.NewCondition("flows.host_id = hosts.id")}},
+				{Kind: "JOIN", Table: sqlbuilder.TableByName("recent"), On: []sqlbuilder.Condition{sqlbuilder.NewCondition("recent.id = flows.id")}},
+			},
+			Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{
+				sqlbuilder.NewColumnCondition("flows.id", "IN", 1, int64(2), uint8(3)),
+				sqlbuilder.Or(sqlbuilder.NewColumnCondition("hosts.name", "LIKE", "h%"), sqlbuilder.NewColumnCondition("up", "=", true), sqlbuilder.SaveBrackets),
+				sqlbuilder.NewColumnCondition("hosts.name", "=", nil),
+				sqlbuilder.NewCondition("whatever = ?", 1),
+			}},
+		}
+		Expect(newCatalog().Validate(dql)).Should(Succeed())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		RegisterTestingT(t)
+		dql := &sqlbuilder.DQL{
+			Select: sqlbuilder.Select{Columns: []string{"name", "flows.nme", "other.id"}},
+			From:   sqlbuilder.FromTableName("flows"),
+			Joins: []sqlbuilder.Join{
+				{Kind: "JOIN", Table: sqlbuilder.TableByName("hosts")},
+				{Kind: "JOIN", Table: sqlbuilder.TableByName("hostz")},
+			},
+			Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{
+				sqlbuilder.NewColumnCondition("flows.id", "=", "1"),
+				sqlbuilder.Not(sqlbuilder.NewColumnCondition("hosts.up", "=", 1), sqlbuilder.OmitBrackets),
+			}},
+		}
+		err := newCatalog().Validate(dql)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).Should(Equal(`unknown table "hostz"
+ambiguous column "name" in tables flows, hosts
+unknown column "nme" in table "flows"
+table "other" of column "id" is not in the scope
+argument 0 of column "flows.id" is string, but the column is int
+argument 0 of column "hosts.up" is int, but the column is bool`))
+	})
+	t.Run("unknown column", func(t *testing.T) {
+		RegisterTestingT(t)
+		dql := &sqlbuilder.DQL{
+			From:  sqlbuilder.FromTableName("flows"),
+			Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewColumnCondition("mtu", ">", 1)}},
+		}
+		Expect(newCatalog().Validate(dql)).Should(MatchError(`unknown column "mtu"`))
+	})
+	t.Run("alias", func(t *testing.T) {
+		RegisterTestingT(t)
+		dql := &sqlbuilder.DQL{
+			Select: sqlbuilder.Select{Columns: []string{"f.id", "h.name", "up"}},
+			From:   sqlbuilder.FromTableName("flows AS f"),
+			Joins: []sqlbuilder.Join{
+				{Kind: "JOIN", Table: sqlbuilder.TableByName("hosts h"), On: []sqlbuilder.Condition{sqlbuilder.NewCondition("f.host_id = h.id")}},
+			},
+			Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewColumnCondition("f.id", "=", 1)}},
+		}
+		Expect(newCatalog().Validate(dql)).Should(Succeed())
+
+		dql.Select.Columns = []string{"f.nme", "flows.id"}
+		dql.Where.Conditions = []sqlbuilder.Condition{sqlbuilder.NewColumnCondition("h.up", "=", "yes")}
+		dql.Joins = append(dql.Joins, sqlbuilder.Join{Kind: "JOIN", Table: sqlbuilder.TableByName("nodes AS n")})
+		Expect(newCatalog().Validate(dql)).Should(MatchError(`unknown table "nodes"
+unknown column "nme" in table "f"
+table "flows" of column "id" is not in the scope
+argument 0 of column "h.up" is string, but the column is bool`))
+	})
+	t.Run("expressions", func(t *testing.T) {
+		RegisterTestingT(t)
+		id := sqlbuilder.NewColumn[int64]("flows", "id")
+		mtu := sqlbuilder.NewColumn[int64]("flows", "mtu")
+		dql := &sqlbuilder.DQL{
+			Select: sqlbuilder.Select{Expressions: []sqlbuilder.Expression{
+				expr.As(expr.Count(id), "n"),
+				expr.Max(mtu),
+				expr.CaseWhen(sqlbuilder.NewColumnCondition("up", "=", "yes"), expr.Val(1)),
+				sqlbuilder.NewSubQuery(&sqlbuilder.DQL{
+					Select: sqlbuilder.Select{Columns: []string{"name"}},
+					From:   sqlbuilder.FromTableName("hosts"),
+				}),
+			}},
+			From:  sqlbuilder.FromTableName("flows"),
+			Group: sqlbuilder.GroupBy(sqlbuilder.NewColumn[string]("", "nme")),
+			Order: sqlbuilder.OrderBy(id.Desc()),
+		}
+		Expect(newCatalog().Validate(dql)).Should(MatchError(`unknown column "mtu" in table "flows"
+unknown column "up"
+unknown column "nme"`))
+	})
+	t.Run("dml", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := &sqlbuilder.Delete{
+			Table: "hosts",
+			Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewColumnCondition("up", "=", "false")}},
+		}
+		Expect(newCatalog().Validate(c)).Should(MatchError(`argument 0 of column "up" is string, but the column is bool`))
+		u := &sqlbuilder.Update{Table: "nodes"}
+		Expect(newCatalog().Validate(u)).Should(MatchError(`unknown table "nodes"`))
+	})
+}
+
+func TestColumnType(t *testing.T) {
+	RegisterTestingT(t)
+	var nilInt *int
+	Expect(sqlbuilder.FloatType.Accept(1)).Should(BeTrue())
+	Expect(sqlbuilder.FloatType.Accept(1.5)).Should(BeTrue())
+	Expect(sqlbuilder.IntType.Accept(1.5)).Should(BeFalse())
+	Expect(sqlbuilder.IntType.Accept(nilInt)).Should(BeTrue())
+	Expect(sqlbuilder.BytesType.Accept([]byte("a"))).Should(BeTrue())
+	Expect(sqlbuilder.BytesType.Accept("a")).Should(BeFalse())
+	Expect(sqlbuilder.TimeType.Accept(time.Now())).Should(BeTrue())
+	Expect(sqlbuilder.AnyType.Accept(struct{}{})).Should(BeTrue())
+}
@@ -16,7 +16,7 @@ type ArgWriter interface {
 func WriteArgs(writer ArgWriter, args ...Arg) error {
 	for _, arg := range args {
 		if err := writer.WriteArg(arg); err != nil {
-			return err
+			return newWriteError(err)
 		}
 	}
 	return nil
@@ -26,7 +26,7 @@ func WriteArgs(writer ArgWriter, args ...Arg) error {
 The clause in sql
 sqlWriter: A interface implemented WriteString, the SQL statements will be wrote into it.
 argWriter: A interface implemented WriteArg, the arguments will be written in the same order as the SQL.
-level: The level describes the indent level at the beginning of each line, the number of indented Spaces is level*2.
+level: The level describes the indent level at the beginning of each line, the indentation is level*Style.Indent, see Style.
 Note: The tool does not check whether the parameters match the parameters of the SQL, because different databases support different formats， please check it manually.
 */
 type Clause interface {
@@ -52,18 +52,31 @@ func CompactLevel(level int) bool {
 	return level < 0
 }
 
-// The substitute of []Clause, it implemented a Parse method
+// The substitute of []Clause, it implemented a Parse method, the nil clauses are skipped.
 type Clauses []Clause
 
 func (cs *Clauses) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
-	for _, c := range *cs {
+	for i, c := range *cs {
+		if c == nil {
+			continue
+		}
 		if err := c.Parse(sqlWriter, argWriter, level); err != nil {
-			return err
+			return LocateIndex(err, "", i)
 		}
 	}
 	return nil
 }
 
+// Append the clauses except nil ones.
+func appendClauses(cs []Clause, clauses Clauses) []Clause {
+	for _, c := range clauses {
+		if c != nil {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
 const (
 	AutoNewline = true
 	DontNewline = false
@@ -87,15 +100,60 @@ func NewSimpleClause(autoEndline bool, sql string, args ...Arg) Clause {
 	}
 }
 
+// Create a clause with named placeholders, see WriteNamed.
+func NewNamedClause(autoEndline bool, sql string, namedArgs any) Clause {
+	return &SimpleClause{
+		SQL:         sql,
+		NamedArgs:   namedArgs,
+		AutoEndline: autoEndline,
+	}
+}
+
+// Create a clause led by the keyword, such as NewKeywordClause(AutoNewline, "GROUP BY", "a, b").
+func NewKeywordClause(autoEndline bool, keyword, sql string, args ...Arg) Clause {
+	return &SimpleClause{
+		Keyword:     keyword,
+		SQL:         sql,
+		Args:        args,
+		AutoEndline: autoEndline,
+	}
+}
+
 type SimpleClause struct {
+	// The Keyword is written before the SQL in the case of Style, they are separated by a space.
+	Keyword     string
 	SQL         string
 	Args        []Arg
 	AutoEndline bool
+	// The SQL contains named placeholders if NamedArgs is not nil, see WriteNamed.
+	// Args must be empty if NamedArgs is used.
+	NamedArgs any
 }
 
 func (c *SimpleClause) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
 	var err error
-	err = WriteStringWithSpace(sqlWriter, c.SQL, level)
+	if c.NamedArgs != nil {
+		if len(c.Args) != 0 {
+			return errMixedArgs
+		}
+		err = c.writeKeyword(sqlWriter, level)
+		if err != nil {
+			return err
+		}
+		err = WriteNamed(sqlWriter, argWriter, c.SQL, c.NamedArgs)
+		if err != nil {
+			return err
+		}
+		if c.AutoEndline {
+			return EndLine(sqlWriter, CompactLevel(level))
+		}
+		return nil
+	}
+	err = c.writeKeyword(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteString(sqlWriter, c.SQL)
 	if err != nil {
 		return err
 	}
@@ -112,6 +170,19 @@ func (c *SimpleClause) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, lev
 	return nil
 }
 
+// Write the indentation and the keyword.
+func (c *SimpleClause) writeKeyword(sqlWriter io.StringWriter, level int) error {
+	err := WriteSpace(sqlWriter, level)
+	if err != nil || c.Keyword == "" {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, c.Keyword)
+	if err != nil || c.SQL == "" {
+		return err
+	}
+	return WriteString(sqlWriter, Space)
+}
+
 func AddClauseLevel(c Clause, level int) Clause {
 	return &addLeveledClause{clause: c, level: level}
 }
@@ -130,9 +201,9 @@ func (c *addLeveledClause) Parse(sqlWriter io.StringWriter, argWriter ArgWriter,
 
 func WriteString(writer io.StringWriter, str string) error {
 	if n, err := writer.WriteString(str); err != nil {
-		return err
+		return newWriteError(err)
 	} else if n != len(str) {
-		return fmt.Errorf("write string failed, expect: %d, real: %d", len(str), n)
+		return newWriteError(fmt.Errorf("write string failed, expect: %d, real: %d", len(str), n))
 	}
 	return nil
 }
@@ -142,9 +213,9 @@ func WriteStringWithSpace(writer io.StringWriter, str string, level int) error {
 		return err
 	}
 	if n, err := writer.WriteString(str); err != nil {
-		return err
+		return newWriteError(err)
 	} else if n != len(str) {
-		return fmt.Errorf("write string failed, expect: %d, real: %d", len(str), n)
+		return newWriteError(fmt.Errorf("write string failed, expect: %d, real: %d", len(str), n))
 	}
 	return nil
 }
@@ -154,22 +225,12 @@ const (
 	Space = " "
 )
 
-// End the line with EOL or Space
+// End the line with Space, or the EOL of the style carried by the sqlWriter.
 func EndLine(sqlWriter io.StringWriter, withSpace bool) error {
 	if withSpace {
-		if n, err := sqlWriter.WriteString(Space); err != nil {
-			return err
-		} else if n != 1 {
-			return fmt.Errorf("write endline failed")
-		}
-		return nil
-	}
-	if n, err := sqlWriter.WriteString(EOL); err != nil {
-		return err
-	} else if n != 1 {
-		return fmt.Errorf("write endline failed")
+		return WriteString(sqlWriter, Space)
 	}
-	return nil
+	return WriteString(sqlWriter, StyleOf(sqlWriter).eol())
 }
 
 // Write indentation with space.
@@ -179,11 +240,11 @@ func WriteSpace(sqlWriter io.StringWriter, level int) error {
 	if CompactLevel(level) {
 		return nil
 	}
-	space := GetSpace(level)
+	space := StyleOf(sqlWriter).Space(level)
 	if n, err := sqlWriter.WriteString(space); err != nil {
-		return err
+		return newWriteError(err)
 	} else if n != len(space) {
-		return fmt.Errorf("write space(level:%d) failed", level)
+		return newWriteError(fmt.Errorf("write space(level:%d) failed", level))
 	}
 	return nil
 }
@@ -196,8 +257,7 @@ const (
 	maxOptimizeLevel  = lenOptimizeSpaces / lenSingleSpace
 )
 
-// Get the Spaces corresponding to the level.
-// And custom the indentation strategy is not supported currently.
+// Get the Spaces corresponding to the level in DefaultStyle, see Style.Space for custom indentation.
 // When you want to write indentation, you should always call WriteSpace instead of GetSpace.
 func GetSpace(level int) string {
 	if CompactLevel(level) {
@@ -0,0 +1,332 @@
+package sqlbuilder
+
+/*
+The clones are deep copies of the clause trees, so that appending to the slices of a clone never affects the original.
+Note: The arguments are shallow copied, and clauses or conditions not implemented in this package are shared
+unless they implement CloneClause() Clause or CloneCondition() Condition, or the children of ParentExpression are cloned.
+*/
+
+// Clone a clause, see the Clone method of the built-in clauses. A nil pointer is returned as it is.
+func CloneClause(c Clause) Clause {
+	switch c := c.(type) {
+	case nil:
+		return nil
+	case *DQL:
+		return c.Clone()
+	case *Update:
+		return c.Clone()
+	case *Delete:
+		return c.Clone()
+	case *Insert:
+		return c.Clone()
+	case *Select:
+		return c.Clone()
+	case *Set:
+		return c.Clone()
+	case From:
+		return c.Clone()
+	case *From:
+		if c == nil {
+			return c
+		}
+		f := c.Clone()
+		return &f
+	case WhereClause:
+		return c.Clone()
+	case *WhereClause:
+		if c == nil {
+			return c
+		}
+		w := c.Clone()
+		return &w
+	case HavingClause:
+		return c.Clone()
+	case *HavingClause:
+		if c == nil {
+			return c
+		}
+		h := c.Clone()
+		return &h
+	case *Join:
+		return c.Clone()
+	case Lock:
+		return c.Clone()
+	case *WithClause:
+		return c.Clone()
+	case *SimpleClause:
+		return c.Clone()
+	case *Clauses:
+		if c == nil {
+			return c
+		}
+		cs := c.Clone()
+		return &cs
+	case *addLeveledClause:
+		if c == nil {
+			return c
+		}
+		return &addLeveledClause{clause: CloneClause(c.clause), level: c.level}
+	case *expressionsClause:
+		if c == nil {
+			return c
+		}
+		return &expressionsClause{head: c.head, exprs: cloneExpressions(c.exprs)}
+	case interface{ CloneClause() Clause }:
+		return c.CloneClause()
+	default:
+		return c
+	}
+}
+
+// Clone a condition, see the Clone method of the built-in conditions.
+func CloneCondition(c Condition) Condition {
+	switch c := c.(type) {
+	case nil:
+		return nil
+	case SimpleCondition:
+		return c.Clone()
+	case ColumnCondition:
+		return c.Clone()
+	case BracketedCondition:
+		return c.Clone()
+	case AndCondition:
+		return c.Clone()
+	case OrCondition:
+		return c.Clone()
+	case NotCondition:
+		return c.Clone()
+	case ScopeCondition:
+		return c.Clone()
+	case SubQuery:
+		return c.Clone()
+	case OrderTerm:
+		return c.Clone()
+	case ExistsCondition:
+		return c.Clone()
+	case interface{ CloneCondition() Condition }:
+		return c.CloneCondition()
+	case ParentExpression:
+		return c.WithChildren(cloneExpressions(c.Children()))
+	default:
+		return c
+	}
+}
+
+func cloneArgs(args []Arg) []Arg {
+	if args == nil {
+		return nil
+	}
+	return append(make([]Arg, 0, len(args)), args...)
+}
+
+func cloneStrings(strs []string) []string {
+	if strs == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(strs)), strs...)
+}
+
+func cloneConditions(cs []Condition) []Condition {
+	if cs == nil {
+		return nil
+	}
+	res := make([]Condition, len(cs))
+	for i, c := range cs {
+		res[i] = CloneCondition(c)
+	}
+	return res
+}
+
+func cloneExpressions(es []Expression) []Expression {
+	if es == nil {
+		return nil
+	}
+	res := make([]Expression, len(es))
+	for i, e := range es {
+		res[i] = CloneCondition(e)
+	}
+	return res
+}
+
+func cloneWith(with With) With {
+	if with == nil {
+		return nil
+	}
+	return CloneClause(with)
+}
+
+func (cs Clauses) Clone() Clauses {
+	if cs == nil {
+		return nil
+	}
+	res := make(Clauses, len(cs))
+	for i, c := range cs {
+		res[i] = CloneClause(c)
+	}
+	return res
+}
+
+func (l *DQL) Clone() *DQL {
+	if l == nil {
+		return nil
+	}
+	return &DQL{
+		With:       cloneWith(l.With),
+		Select:     *l.Select.Clone(),
+		From:       l.From.Clone(),
+		Joins:      cloneJoins(l.Joins),
+		Where:      l.Where.Clone(),
+		Group:      CloneClause(l.Group),
+		Having:     l.Having.Clone(),
+		Order:      CloneClause(l.Order),
+		Limit:      CloneClause(l.Limit),
+		Lock:       l.Lock.Clone(),
+		Additional: l.Additional.Clone(),
+	}
+}
+
+func (l *Update) Clone() *Update {
+	if l == nil {
+		return nil
+	}
+	return &Update{
+		With:       cloneWith(l.With),
+		Table:      l.Table,
+		Set:        *l.Set.Clone(),
+		Where:      l.Where.Clone(),
+		Additional: l.Additional.Clone(),
+	}
+}
+
+func (l *Delete) Clone() *Delete {
+	if l == nil {
+		return nil
+	}
+	return &Delete{
+		With:       cloneWith(l.With),
+		Table:      l.Table,
+		Where:      l.Where.Clone(),
+		Additional: l.Additional.Clone(),
+	}
+}
+
+func (c *Select) Clone() *Select {
+	if c == nil {
+		return nil
+	}
+	return &Select{
+		Columns:     cloneStrings(c.Columns),
+		Args:        cloneArgs(c.Args),
+		Expressions: cloneExpressions(c.Expressions),
+	}
+}
+
+func (c *Set) Clone() *Set {
+	if c == nil {
+		return nil
+	}
+	return &Set{
+		Assignments: cloneStrings(c.Assignments),
+		Args:        cloneArgs(c.Args),
+	}
+}
+
+func (c Table) Clone() Table {
+	c.Clause = CloneClause(c.Clause)
+	return c
+}
+
+func (c From) Clone() From {
+	return From{Table: c.Table.Clone()}
+}
+
+func cloneJoins(joins []Join) []Join {
+	if joins == nil {
+		return nil
+	}
+	res := make([]Join, len(joins))
+	for i := range joins {
+		res[i] = *joins[i].Clone()
+	}
+	return res
+}
+
+func (c *Join) Clone() *Join {
+	if c == nil {
+		return nil
+	}
+	return &Join{Kind: c.Kind, Table: c.Table.Clone(), On: cloneConditions(c.On)}
+}
+
+func (c WhereClause) Clone() WhereClause {
+	return WhereClause{Conditions: cloneConditions(c.Conditions)}
+}
+
+func (c HavingClause) Clone() HavingClause {
+	return HavingClause{Conditions: cloneConditions(c.Conditions)}
+}
+
+func (c *WithClause) Clone() *WithClause {
+	if c == nil {
+		return nil
+	}
+	if c.Tables == nil {
+		return &WithClause{}
+	}
+	tables := make([]Table, len(c.Tables))
+	for i, t := range c.Tables {
+		tables[i] = t.Clone()
+	}
+	return &WithClause{Tables: tables}
+}
+
+func (c *SimpleClause) Clone() *SimpleClause {
+	if c == nil {
+		return nil
+	}
+	res := *c
+	res.Args = cloneArgs(c.Args)
+	return &res
+}
+
+func (c SimpleCondition) Clone() SimpleCondition {
+	c.Args = cloneArgs(c.Args)
+	return c
+}
+
+func (c ColumnCondition) Clone() ColumnCondition {
+	c.Args = cloneArgs(c.Args)
+	return c
+}
+
+func (c BracketedCondition) Clone() BracketedCondition {
+	return BracketedCondition{Condition: CloneCondition(c.Condition)}
+}
+
+func (c AndCondition) Clone() AndCondition {
+	return AndCondition{L: CloneCondition(c.L), R: CloneCondition(c.R), Bracket: c.Bracket}
+}
+
+func (c OrCondition) Clone() OrCondition {
+	return OrCondition{L: CloneCondition(c.L), R: CloneCondition(c.R), Bracket: c.Bracket}
+}
+
+func (c NotCondition) Clone() NotCondition {
+	return NotCondition{Condition: CloneCondition(c.Condition), Bracket: c.Bracket}
+}
+
+func (c ScopeCondition) Clone() ScopeCondition {
+	return ScopeCondition{Table: c.Table, Condition: CloneCondition(c.Condition)}
+}
+
+func (t OrderTerm) Clone() OrderTerm {
+	return OrderTerm{Expression: CloneCondition(t.Expression), Column: t.Column, Desc: t.Desc}
+}
+
+func (e SubQuery) Clone() SubQuery {
+	return SubQuery{Clause: CloneClause(e.Clause)}
+}
+
+func (c ExistsCondition) Clone() ExistsCondition {
+	return ExistsCondition{SubQuery: c.SubQuery.Clone()}
+}
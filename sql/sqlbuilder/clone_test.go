@@ -0,0 +1,117 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/expr"
+)
+
+func TestCloneDQL(t *testing.T) {
+	newDQL := func() *sqlbuilder.DQL {
+		conditions := make([]sqlbuilder.Condition, 1, 4)
+		conditions[0] = sqlbuilder.And(sqlbuilder.NewCondition("x = ?", 1), sqlbuilder.NewCondition("y = ?", 2), sqlbuilder.SaveBrackets)
+		return &sqlbuilder.DQL{
+			With: &sqlbuilder.WithClause{
+				Tables: []sqlbuilder.Table{
+					sqlbuilder.NameAsTable("a", &sqlbuilder.DQL{From: sqlbuilder.FromTableName("b")}),
+				},
+			},
+			Select:     sqlbuilder.Select{Columns: make([]string, 0, 4), Args: make([]sqlbuilder.Arg, 0, 4)},
+			From:       sqlbuilder.FromTableName("a"),
+			Where:      sqlbuilder.WhereClause{Conditions: conditions},
+			Group:      sqlbuilder.MakeGroupby("x"),
+			Order:      sqlbuilder.MakeOrderby("y"),
+			Limit:      sqlbuilder.MakeLimit("?", 10),
+			Additional: make([]sqlbuilder.Clause, 0, 4),
+		}
+	}
+	t.Run("equal", func(t *testing.T) {
+		RegisterTestingT(t)
+		l := newDQL()
+		c := l.Clone()
+		Expect(c).Should(Equal(l))
+		Expect(c).ShouldNot(BeIdenticalTo(l))
+	})
+	t.Run("no aliasing", func(t *testing.T) {
+		RegisterTestingT(t)
+		l := newDQL()
+		c1, c2 := l.Clone(), l.Clone()
+		c1.Where.Conditions = append(c1.Where.Conditions, sqlbuilder.NewCondition("z = ?", 3))
+		c2.Where.Conditions = append(c2.Where.Conditions, sqlbuilder.NewCondition("w = ?", 4))
+		c1.Select.Columns = append(c1.Select.Columns, "x")
+		c2.Select.Columns = append(c2.Select.Columns, "y")
+		c1.Additional = append(c1.Additional, sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "OFFSET 1"))
+		c2.With.(*sqlbuilder.WithClause).Tables[0].Clause.(*sqlbuilder.DQL).Where.Conditions = []sqlbuilder.Condition{
+			sqlbuilder.NewCondition("v = ?", 5),
+		}
+		c2.Limit.(*sqlbuilder.SimpleClause).Args[0] = 20
+
+		sql1, args1, err := sqlbuilder.Build(c1, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql1).Should(Equal("WITH a AS ( SELECT * FROM b ) SELECT x FROM a WHERE (x = ? AND y = ?) AND z = ? GROUP BY x ORDER BY y LIMIT ? OFFSET 1 "))
+		Expect(args1).Should(Equal([]sqlbuilder.Arg{1, 2, 3, 10}))
+		sql2, args2, err := sqlbuilder.Build(c2, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql2).Should(Equal("WITH a AS ( SELECT * FROM b WHERE v = ? ) SELECT y FROM a WHERE (x = ? AND y = ?) AND w = ? GROUP BY x ORDER BY y LIMIT ? "))
+		Expect(args2).Should(Equal([]sqlbuilder.Arg{5, 1, 2, 4, 20}))
+		Expect(l).Should(Equal(newDQL()))
+	})
+	t.Run("nil", func(t *testing.T) {
+		RegisterTestingT(t)
+		var l *sqlbuilder.DQL
+		Expect(l.Clone()).Should(BeNil())
+		Expect(sqlbuilder.CloneClause(nil)).Should(BeNil())
+		Expect(sqlbuilder.CloneCondition(nil)).Should(BeNil())
+		for _, c := range []sqlbuilder.Clause{(*sqlbuilder.Select)(nil), (*sqlbuilder.Set)(nil), (*sqlbuilder.From)(nil),
+			(*sqlbuilder.WhereClause)(nil), (*sqlbuilder.WithClause)(nil), (*sqlbuilder.SimpleClause)(nil), (*sqlbuilder.Clauses)(nil)} {
+			Expect(sqlbuilder.CloneClause(c)).Should(BeNil())
+		}
+	})
+}
+
+func TestCloneDML(t *testing.T) {
+	RegisterTestingT(t)
+	u := &sqlbuilder.Update{
+		Table: "a",
+		Set:   sqlbuilder.Set{Assignments: make([]string, 1, 4), Args: make([]sqlbuilder.Arg, 1, 4)},
+	}
+	c := u.Clone()
+	Expect(c).Should(Equal(u))
+	c.Set.Assignments = append(c.Set.Assignments, "x = ?")
+	Expect(u.Set.Assignments).Should(HaveLen(1))
+
+	d := &sqlbuilder.Delete{Table: "a", Where: sqlbuilder.WhereClause{Conditions: make([]sqlbuilder.Condition, 0, 4)}}
+	cd := sqlbuilder.CloneClause(d).(*sqlbuilder.Delete)
+	Expect(cd).Should(Equal(d))
+	cd.Where.Conditions = append(cd.Where.Conditions, sqlbuilder.NewCondition("x"))
+	Expect(d.Where.Conditions).Should(BeEmpty())
+}
+
+func TestCloneCondition(t *testing.T) {
+	RegisterTestingT(t)
+	args := make([]sqlbuilder.Arg, 1, 4)
+	c := sqlbuilder.Not(sqlbuilder.Or(
+		sqlbuilder.Bracket(sqlbuilder.NewCondition("x = ?", args...)),
+		sqlbuilder.ScopeCondition{Table: "t", Condition: sqlbuilder.NewCondition("y")},
+		sqlbuilder.OmitBrackets,
+	), sqlbuilder.SaveBrackets)
+	cc := sqlbuilder.CloneCondition(c)
+	Expect(cc).Should(Equal(c))
+	inner := cc.(sqlbuilder.NotCondition).Condition.(sqlbuilder.OrCondition).L.(sqlbuilder.BracketedCondition).Condition.(sqlbuilder.SimpleCondition)
+	inner.Args[0] = 1
+	Expect(args[0]).Should(BeNil())
+
+	t.Run("sub queries", func(t *testing.T) {
+		RegisterTestingT(t)
+		sub := &sqlbuilder.DQL{From: sqlbuilder.FromTableName("b")}
+		c := expr.Cmp(expr.Col("a.id"), "IN", sqlbuilder.NewSubQuery(sub))
+		cc := sqlbuilder.CloneCondition(sqlbuilder.And(c, sqlbuilder.Exists(sub), sqlbuilder.OmitBrackets)).(sqlbuilder.AndCondition)
+		Expect(cc.L).Should(Equal(c))
+		cc.L.(expr.Binary).Right.(sqlbuilder.SubQuery).Clause.(*sqlbuilder.DQL).From = sqlbuilder.FromTableName("x")
+		cc.R.(sqlbuilder.ExistsCondition).SubQuery.Clause.(*sqlbuilder.DQL).From = sqlbuilder.FromTableName("y")
+		Expect(sub.From).Should(Equal(sqlbuilder.FromTableName("b")))
+	})
+}
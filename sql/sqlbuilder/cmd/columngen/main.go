@@ -0,0 +1,250 @@
+/*
+Columngen generates the sets of sqlbuilder.Column from struct definitions.
+
+	//go:generate go run github.com/everoute/util/sql/sqlbuilder/cmd/columngen -type Flow -table flows
+
+For the struct Flow in the file, it generates the variable FlowColumns into flow_columns.go,
+the fields of FlowColumns are Column[T] of the exported fields of Flow, where T is the type of the field.
+The imports of the file used by the field types, such as "time" for time.Time, are copied into the output.
+The column name is the "db" tag of the field, or the snake case of the field name, the fields tagged "-" are skipped.
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func main() {
+	var (
+		file     = flag.String("file", os.Getenv("GOFILE"), "the file declaring the struct, $GOFILE by default")
+		typeName = flag.String("type", "", "the name of the struct")
+		table    = flag.String("table", "", "the table of the columns, the columns are unqualified if it's empty")
+		output   = flag.String("output", "", "the output file, <snake case of type>_columns.go by default")
+	)
+	flag.Parse()
+	if *file == "" || *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	src, err := generate(*file, *typeName, *table)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "columngen:", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		*output = filepath.Join(filepath.Dir(*file), snakeCase(*typeName)+"_columns.go")
+	}
+	// The generated file is readable like other source files.
+	if err = os.WriteFile(*output, src, 0o644); err != nil { //nolint:gosec
+		fmt.Fprintln(os.Stderr, "columngen:", err)
+		os.Exit(1)
+	}
+}
+
+const sqlbuilderPath = "github.com/everoute/util/sql/sqlbuilder"
+
+type column struct {
+	field string
+	typ   string
+	name  string
+}
+
+// Generate the source of the column set of the struct in the file.
+func generate(file, typeName, table string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	st, err := findStruct(f, typeName)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		columns []column
+		// The import specs by paths.
+		imports = make(map[string]string)
+	)
+	for _, field := range st.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			// The tag is a raw or interpreted string literal.
+			value, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return nil, fmt.Errorf("bad tag %s at %s: %w", field.Tag.Value, fset.Position(field.Tag.Pos()), err)
+			}
+			tag = reflect.StructTag(value).Get(sqlbuilder.NamedArgTag)
+		}
+		if tag == "-" {
+			continue
+		}
+		exported := false
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			exported = true
+			c := column{field: name.Name, typ: types.ExprString(field.Type), name: tag}
+			if c.name == "" {
+				c.name = snakeCase(name.Name)
+			}
+			columns = append(columns, c)
+		}
+		if exported {
+			if err = collectImports(f, field.Type, imports); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var buff bytes.Buffer
+	fmt.Fprintf(&buff, "// Code generated by columngen. DO NOT EDIT.\n\npackage %s\n\n", f.Name.Name)
+	delete(imports, sqlbuilderPath)
+	if len(imports) == 0 {
+		fmt.Fprintf(&buff, "import %q\n\n", sqlbuilderPath)
+	} else {
+		fmt.Fprintf(&buff, "import (\n%s\n%q\n)\n\n", importGroups(imports), sqlbuilderPath)
+	}
+	fmt.Fprintf(&buff, "// The columns of %s.\nvar %sColumns = struct {\n", typeName, typeName)
+	for _, c := range columns {
+		fmt.Fprintf(&buff, "%s sqlbuilder.Column[%s]\n", c.field, c.typ)
+	}
+	fmt.Fprintf(&buff, "}{\n")
+	for _, c := range columns {
+		fmt.Fprintf(&buff, "%s: sqlbuilder.NewColumn[%s](%q, %q),\n", c.field, c.typ, table, c.name)
+	}
+	fmt.Fprintf(&buff, "}\n")
+	return format.Source(buff.Bytes())
+}
+
+// Collect the import specs of the packages used by the type expression, such as `"time"` for time.Time.
+func collectImports(f *ast.File, typ ast.Expr, imports map[string]string) error {
+	var err error
+	ast.Inspect(typ, func(node ast.Node) bool {
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok || err != nil {
+			return err == nil
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		var importPath, spec string
+		if importPath, spec, err = importOf(f, pkg.Name); err == nil {
+			imports[importPath] = spec
+		}
+		return false
+	})
+	return err
+}
+
+// Get the import path and spec of the package name in the file, the name is assumed by the path if the import is not named.
+func importOf(f *ast.File, name string) (string, string, error) {
+	for _, imp := range f.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return "", "", err
+		}
+		if imp.Name != nil {
+			if imp.Name.Name == name {
+				return importPath, imp.Name.Name + " " + imp.Path.Value, nil
+			}
+			continue
+		}
+		if assumedName(importPath) == name {
+			return importPath, imp.Path.Value, nil
+		}
+	}
+	return "", "", fmt.Errorf("the import of package %s is not found", name)
+}
+
+// Assume the package name of the import path, such as "yaml" for "gopkg.in/yaml.v3" and "sqlite" for "modernc.org/sqlite".
+func assumedName(importPath string) string {
+	base := path.Base(importPath)
+	if len(base) > 1 && base[0] == 'v' && strings.TrimLeft(base[1:], "0123456789") == "" && path.Dir(importPath) != "." {
+		base = path.Base(path.Dir(importPath))
+	}
+	if i := strings.IndexByte(base, '.'); i > 0 {
+		base = base[:i]
+	}
+	base = strings.TrimPrefix(base, "go-")
+	return strings.ReplaceAll(base, "-", "_")
+}
+
+// Format the import specs in groups of the standard library and the others, each group ends with a blank line.
+func importGroups(imports map[string]string) string {
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var std, others strings.Builder
+	for _, p := range paths {
+		group := &others
+		if !strings.Contains(strings.Split(p, "/")[0], ".") {
+			group = &std
+		}
+		group.WriteString(imports[p] + "\n")
+	}
+	var builder strings.Builder
+	for _, group := range []string{std.String(), others.String()} {
+		if group != "" {
+			builder.WriteString(group + "\n")
+		}
+	}
+	return builder.String()
+}
+
+func findStruct(f *ast.File, typeName string) (*ast.StructType, error) {
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if ts.Name.Name != typeName {
+				continue
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				return nil, fmt.Errorf("type %s is not a struct", typeName)
+			}
+			return st, nil
+		}
+	}
+	return nil, fmt.Errorf("struct %s is not found", typeName)
+}
+
+// Convert the name to snake case, such as "FlowID" to "flow_id".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				builder.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
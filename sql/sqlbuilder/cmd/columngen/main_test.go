@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestGenerate(t *testing.T) {
+	RegisterTestingT(t)
+	file := filepath.Join(t.TempDir(), "flow.go")
+	Expect(os.WriteFile(file, []byte(`package model
+
+type Flow struct {
+	FlowID   int64
+	Name     string `+"`db:\"flow_name\"`"+`
+	Labels   map[string]string `+"`db:\"-\"`"+`
+	internal bool
+	SrcIP, DstIP *string
+}
+`), 0o600)).Should(Succeed())
+
+	src, err := generate(file, "Flow", "flows")
+	Expect(err).Should(Succeed())
+	Expect(string(src)).Should(Equal(`// Code generated by columngen. DO NOT EDIT.
+
+package model
+
+import "github.com/everoute/util/sql/sqlbuilder"
+
+// The columns of Flow.
+var FlowColumns = struct {
+	FlowID sqlbuilder.Column[int64]
+	Name   sqlbuilder.Column[string]
+	SrcIP  sqlbuilder.Column[*string]
+	DstIP  sqlbuilder.Column[*string]
+}{
+	FlowID: sqlbuilder.NewColumn[int64]("flows", "flow_id"),
+	Name:   sqlbuilder.NewColumn[string]("flows", "flow_name"),
+	SrcIP:  sqlbuilder.NewColumn[*string]("flows", "src_ip"),
+	DstIP:  sqlbuilder.NewColumn[*string]("flows", "dst_ip"),
+}
+`))
+
+	_, err = generate(file, "Missing", "")
+	Expect(err).Should(MatchError("struct Missing is not found"))
+}
+
+func TestGenerateImports(t *testing.T) {
+	RegisterTestingT(t)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "host.go")
+	Expect(os.WriteFile(file, []byte(`package model
+
+import (
+	"net"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+	"github.com/google/uuid"
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+type Host struct {
+	ID        uuid.UUID
+	IP        net.IP
+	Spec      *yaml.Node
+	Created   time.Time
+	Timeouts  map[string]time.Duration
+	Type      sqlbuilder.ColumnType
+	internal  sqlbuilder.Dialect
+}
+`), 0o600)).Should(Succeed())
+
+	src, err := generate(file, "Host", "")
+	Expect(err).Should(Succeed())
+	Expect(string(src)).Should(Equal(`// Code generated by columngen. DO NOT EDIT.
+
+package model
+
+import (
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+// The columns of Host.
+var HostColumns = struct {
+	ID       sqlbuilder.Column[uuid.UUID]
+	IP       sqlbuilder.Column[net.IP]
+	Spec     sqlbuilder.Column[*yaml.Node]
+	Created  sqlbuilder.Column[time.Time]
+	Timeouts sqlbuilder.Column[map[string]time.Duration]
+	Type     sqlbuilder.Column[sqlbuilder.ColumnType]
+}{
+	ID:       sqlbuilder.NewColumn[uuid.UUID]("", "id"),
+	IP:       sqlbuilder.NewColumn[net.IP]("", "ip"),
+	Spec:     sqlbuilder.NewColumn[*yaml.Node]("", "spec"),
+	Created:  sqlbuilder.NewColumn[time.Time]("", "created"),
+	Timeouts: sqlbuilder.NewColumn[map[string]time.Duration]("", "timeouts"),
+	Type:     sqlbuilder.NewColumn[sqlbuilder.ColumnType]("", "type"),
+}
+`))
+
+	Expect(os.WriteFile(file, []byte("package model\n\ntype Host struct {\n\tIP net.IP\n}\n"), 0o600)).Should(Succeed())
+	_, err = generate(file, "Host", "")
+	Expect(err).Should(MatchError("the import of package net is not found"))
+}
+
+func TestGenerateQuotedTag(t *testing.T) {
+	RegisterTestingT(t)
+	file := filepath.Join(t.TempDir(), "host.go")
+	Expect(os.WriteFile(file, []byte("package model\n\ntype Host struct {\n\tName string \"db:\\\"host_name\\\"\"\n\tSkip string \"db:\\\"-\\\"\"\n}\n"), 0o600)).Should(Succeed())
+	src, err := generate(file, "Host", "")
+	Expect(err).Should(Succeed())
+	Expect(string(src)).Should(ContainSubstring(`Name: sqlbuilder.NewColumn[string]("", "host_name"),`))
+	Expect(string(src)).ShouldNot(ContainSubstring("Skip"))
+}
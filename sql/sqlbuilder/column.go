@@ -0,0 +1,118 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"io"
+)
+
+/*
+Column is a typed column, the methods produce conditions and orderings for it,
+so that comparing a Column[int64] with a string fails to compile.
+The conditions are ColumnCondition, they can be used in WhereClause and validated by Catalog.
+*/
+type Column[T any] struct {
+	// The table is optional, the column name will be qualified by it if it's not empty.
+	Table string
+	Name  string
+}
+
+func NewColumn[T any](table, name string) Column[T] {
+	return Column[T]{
+		Table: table,
+		Name:  name,
+	}
+}
+
+// The name qualified by the table, it can be used in Select.Columns.
+func (c Column[T]) String() string {
+	if c.Table == "" {
+		return c.Name
+	}
+	return c.Table + "." + c.Name
+}
+
+// Parse the column as an Expression.
+func (c Column[T]) Parse(sqlWriter io.StringWriter, argWriter ArgWriter) error {
+	return WriteString(sqlWriter, c.String())
+}
+
+// The column referenced in expressions, it's validated by Catalog.
+func (c Column[T]) columnRef() (table, name string) {
+	return c.Table, c.Name
+}
+
+func (c Column[T]) Eq(value T) ColumnCondition {
+	return NewColumnCondition(c.String(), "=", value)
+}
+
+func (c Column[T]) Ne(value T) ColumnCondition {
+	return NewColumnCondition(c.String(), "<>", value)
+}
+
+func (c Column[T]) Gt(value T) ColumnCondition {
+	return NewColumnCondition(c.String(), ">", value)
+}
+
+func (c Column[T]) Ge(value T) ColumnCondition {
+	return NewColumnCondition(c.String(), ">=", value)
+}
+
+func (c Column[T]) Lt(value T) ColumnCondition {
+	return NewColumnCondition(c.String(), "<", value)
+}
+
+func (c Column[T]) Le(value T) ColumnCondition {
+	return NewColumnCondition(c.String(), "<=", value)
+}
+
+func (c Column[T]) Between(low, high T) ColumnCondition {
+	return NewColumnCondition(c.String(), "BETWEEN", low, high)
+}
+
+// IN with empty values is always false.
+func (c Column[T]) In(values ...T) ColumnCondition {
+	return NewColumnCondition(c.String(), "IN", toArgs(values)...)
+}
+
+// NOT IN with empty values is always true.
+func (c Column[T]) NotIn(values ...T) ColumnCondition {
+	return NewColumnCondition(c.String(), "NOT IN", toArgs(values)...)
+}
+
+func (c Column[T]) IsNull() ColumnCondition {
+	return NewColumnCondition(c.String(), "IS NULL")
+}
+
+func (c Column[T]) IsNotNull() ColumnCondition {
+	return NewColumnCondition(c.String(), "IS NOT NULL")
+}
+
+func (c Column[T]) Asc() OrderTerm {
+	return Asc(c)
+}
+
+func (c Column[T]) Desc() OrderTerm {
+	return Desc(c)
+}
+
+// LIKE is available for string columns only, such as Like(name, "eth%").
+func Like(c Column[string], pattern string) ColumnCondition {
+	return NewColumnCondition(c.String(), "LIKE", pattern)
+}
+
+func toArgs[T any](values []T) []Arg {
+	args := make([]Arg, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+// Get the qualified names of columns for Select.Columns, such as Columns(id, name).
+func Columns(columns ...fmt.Stringer) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.String()
+	}
+	return names
+}
@@ -0,0 +1,62 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+var (
+	flowID   = sqlbuilder.NewColumn[int64]("flows", "id")
+	flowName = sqlbuilder.NewColumn[string]("flows", "name")
+	flowMTU  = sqlbuilder.NewColumn[int]("", "mtu")
+)
+
+func TestColumn(t *testing.T) {
+	t.Run("conditions", func(t *testing.T) {
+		RegisterTestingT(t)
+		dql := &sqlbuilder.DQL{
+			Select: sqlbuilder.Select{Columns: sqlbuilder.Columns(flowID, flowName, flowMTU)},
+			From:   sqlbuilder.FromTableName("flows"),
+			Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{
+				flowID.In(1, 2),
+				flowID.NotIn(),
+				sqlbuilder.Like(flowName, "eth%"),
+				sqlbuilder.Or(flowMTU.Gt(1500), flowMTU.Le(576), sqlbuilder.SaveBrackets),
+				flowMTU.Between(1, 9000),
+				flowName.Ne("lo"),
+				flowName.IsNotNull(),
+				sqlbuilder.Not(flowID.IsNull(), sqlbuilder.OmitBrackets),
+				flowID.Eq(3),
+				flowID.Ge(4),
+				flowID.Lt(5),
+			}},
+			Order: sqlbuilder.OrderBy(flowID.Desc(), flowName.Asc()),
+		}
+		sql, args, err := sqlbuilder.Build(dql, sqlbuilder.PostgreSQL, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT flows.id, flows.name, mtu FROM flows WHERE flows.id IN ($1, $2) AND 1 = 1 AND flows.name LIKE $3 " +
+			"AND (mtu > $4 OR mtu <= $5) AND mtu BETWEEN $6 AND $7 AND flows.name <> $8 AND flows.name IS NOT NULL " +
+			"AND NOT flows.id IS NULL AND flows.id = $9 AND flows.id >= $10 AND flows.id < $11 ORDER BY flows.id DESC, flows.name ASC "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{int64(1), int64(2), "eth%", 1500, 576, 1, 9000, "lo", int64(3), int64(4), int64(5)}))
+		Expect(newCatalog().Validate(dql)).Should(MatchError(ContainSubstring(`unknown column "mtu"`)))
+	})
+	t.Run("query", func(t *testing.T) {
+		RegisterTestingT(t)
+		q := sqlbuilder.NewQuery("flows").Select(sqlbuilder.Columns(flowID)...).Where(flowName.Eq("eth0"))
+		sql, args, err := sqlbuilder.Build(q, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT flows.id FROM flows WHERE flows.name = ? "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"eth0"}))
+	})
+	t.Run("order by nothing", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(sqlbuilder.OrderBy()).Should(BeNil())
+		q := sqlbuilder.NewQuery("flows").OrderByTerms()
+		sql, _, err := sqlbuilder.Build(q, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT * FROM flows "))
+	})
+}
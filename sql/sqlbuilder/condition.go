@@ -1,6 +1,10 @@
 package sqlbuilder
 
-import "io"
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
 
 type Condition interface {
 	Parse(sqlWriter io.StringWriter, argWriter ArgWriter) error
@@ -11,9 +15,18 @@ type Condition interface {
 type SimpleCondition struct {
 	Str  string
 	Args []Arg
+	// The Str contains named placeholders if NamedArgs is not nil, see WriteNamed.
+	// Args must be empty if NamedArgs is used.
+	NamedArgs any
 }
 
 func (c SimpleCondition) Parse(sqlWriter io.StringWriter, argWriter ArgWriter) error {
+	if c.NamedArgs != nil {
+		if len(c.Args) != 0 {
+			return errMixedArgs
+		}
+		return WriteNamed(sqlWriter, argWriter, c.Str, c.NamedArgs)
+	}
 	var err error
 	err = WriteString(sqlWriter, c.Str)
 	if err != nil {
@@ -33,6 +46,14 @@ func NewCondition(str string, args ...Arg) SimpleCondition {
 	}
 }
 
+// Create a condition with named placeholders, such as NewNamedCondition("name = :name", map[string]any{"name": "eth0"}).
+func NewNamedCondition(str string, namedArgs any) SimpleCondition {
+	return SimpleCondition{
+		Str:       str,
+		NamedArgs: namedArgs,
+	}
+}
+
 type CustomCondition func(sqlWriter io.StringWriter, argWriter ArgWriter) error
 
 func NewCustomCondition(fn func(sqlWriter io.StringWriter, argWriter ArgWriter) error) CustomCondition {
@@ -60,7 +81,7 @@ func (c BracketedCondition) Parse(sqlWriter io.StringWriter, argWriter ArgWriter
 	}
 	err = c.Condition.Parse(sqlWriter, argWriter)
 	if err != nil {
-		return err
+		return Locate(err, "Condition")
 	}
 	err = WriteString(sqlWriter, ")")
 	if err != nil {
@@ -69,12 +90,16 @@ func (c BracketedCondition) Parse(sqlWriter io.StringWriter, argWriter ArgWriter
 	return nil
 }
 
+// Bracket the condition, it returns nil if the condition is nil.
 func Bracket(condition Condition) Condition {
+	if condition == nil {
+		return nil
+	}
 	return BracketedCondition{Condition: condition}
 }
 
 func BracketIf(condition Condition, bracket bool) Condition {
-	if bracket {
+	if bracket && condition != nil {
 		return BracketedCondition{Condition: condition}
 	}
 	return condition
@@ -96,15 +121,15 @@ func (c AndCondition) Parse(sqlWriter io.StringWriter, argWriter ArgWriter) erro
 	}
 	err = c.L.Parse(sqlWriter, argWriter)
 	if err != nil {
-		return err
+		return Locate(err, "L")
 	}
-	err = WriteString(sqlWriter, " AND ")
+	err = WriteKeyword(sqlWriter, " AND ")
 	if err != nil {
 		return err
 	}
 	err = c.R.Parse(sqlWriter, argWriter)
 	if err != nil {
-		return err
+		return Locate(err, "R")
 	}
 	if c.Bracket {
 		err = WriteString(sqlWriter, ")")
@@ -148,15 +173,15 @@ func (c OrCondition) Parse(sqlWriter io.StringWriter, argWriter ArgWriter) error
 	}
 	err = c.L.Parse(sqlWriter, argWriter)
 	if err != nil {
-		return err
+		return Locate(err, "L")
 	}
-	err = WriteString(sqlWriter, " OR ")
+	err = WriteKeyword(sqlWriter, " OR ")
 	if err != nil {
 		return err
 	}
 	err = c.R.Parse(sqlWriter, argWriter)
 	if err != nil {
-		return err
+		return Locate(err, "R")
 	}
 	if c.Bracket {
 		err = WriteString(sqlWriter, ")")
@@ -197,13 +222,13 @@ func (c NotCondition) Parse(sqlWriter io.StringWriter, argWriter ArgWriter) erro
 			return err
 		}
 	}
-	err = WriteString(sqlWriter, "NOT ")
+	err = WriteKeyword(sqlWriter, "NOT ")
 	if err != nil {
 		return err
 	}
 	err = c.Condition.Parse(sqlWriter, argWriter)
 	if err != nil {
-		return err
+		return Locate(err, "Condition")
 	}
 	if c.Bracket {
 		err = WriteString(sqlWriter, ")")
@@ -214,9 +239,128 @@ func (c NotCondition) Parse(sqlWriter io.StringWriter, argWriter ArgWriter) erro
 	return nil
 }
 
+// Negate the condition, it returns nil if the condition is nil.
 func Not(condition Condition, bracket bool) Condition {
+	if condition == nil {
+		return nil
+	}
 	return NotCondition{
 		Condition: condition,
 		Bracket:   bracket,
 	}
 }
+
+// ExistsCondition is "EXISTS ( SELECT ... )", use Not for NOT EXISTS.
+type ExistsCondition struct {
+	SubQuery SubQuery
+}
+
+// Create an EXISTS condition of the sub query, such as Exists(q.Select("1").DQL()).
+func Exists(clause Clause) ExistsCondition {
+	return ExistsCondition{SubQuery: NewSubQuery(clause)}
+}
+
+func (c ExistsCondition) Parse(sqlWriter io.StringWriter, argWriter ArgWriter) error {
+	err := WriteKeyword(sqlWriter, "EXISTS ")
+	if err != nil {
+		return err
+	}
+	return Locate(c.SubQuery.Parse(sqlWriter, argWriter), "SubQuery")
+}
+
+// ColumnCondition compares a column with arguments, the placeholders are generated for the dialect.
+// Unlike SimpleCondition, it's typed so that it can be validated by Catalog.
+type ColumnCondition struct {
+	// The column name, it can be qualified by the table name, such as "flows.id".
+	Column string
+	// One of =, <>, !=, >, >=, <, <=, LIKE, NOT LIKE, IN, NOT IN, BETWEEN, IS NULL and IS NOT NULL.
+	Op   string
+	Args []Arg
+	// The escape character of LIKE and NOT LIKE, such as `\`, it's not allowed for other operators.
+	Escape string
+}
+
+func NewColumnCondition(column string, op string, args ...Arg) ColumnCondition {
+	return ColumnCondition{
+		Column: column,
+		Op:     op,
+		Args:   args,
+	}
+}
+
+// IN with empty Args is always false, and NOT IN with empty Args is always true.
+func (c ColumnCondition) Parse(sqlWriter io.StringWriter, argWriter ArgWriter) error {
+	var (
+		op          = strings.ToUpper(c.Op)
+		sep         = ", "
+		open, close string
+	)
+	switch op {
+	case "IS NULL", "IS NOT NULL":
+		if len(c.Args) != 0 {
+			return NewInvalidError("%s %s expects no arguments, but got %d", c.Column, op, len(c.Args))
+		}
+	case "IN", "NOT IN":
+		if len(c.Args) == 0 {
+			if op == "IN" {
+				return WriteString(sqlWriter, "1 = 0")
+			}
+			return WriteString(sqlWriter, "1 = 1")
+		}
+		open, close = "(", ")"
+	case "BETWEEN":
+		if len(c.Args) != 2 {
+			return NewInvalidError("%s BETWEEN expects 2 arguments, but got %d", c.Column, len(c.Args))
+		}
+		sep = " AND "
+	case "=", "<>", "!=", ">", ">=", "<", "<=", "LIKE", "NOT LIKE":
+		if len(c.Args) != 1 {
+			return NewInvalidError("%s %s expects 1 argument, but got %d", c.Column, op, len(c.Args))
+		}
+	default:
+		return NewInvalidError("unknown operator %q of column %s", c.Op, c.Column)
+	}
+	if c.Escape != "" && (op != "LIKE" && op != "NOT LIKE" || utf8.RuneCountInString(c.Escape) != 1) {
+		return NewInvalidError("bad escape %q of %s %s", c.Escape, c.Column, op)
+	}
+	// Write the pieces one by one to avoid concatenating strings.
+	if err := WriteString(sqlWriter, c.Column); err != nil {
+		return err
+	}
+	if err := WriteString(sqlWriter, " "); err != nil {
+		return err
+	}
+	if err := WriteKeyword(sqlWriter, op); err != nil {
+		return err
+	}
+	if len(c.Args) != 0 {
+		dialect, base := placeholderBase(argWriter)
+		for i := range c.Args {
+			if i == 0 {
+				if err := WriteString(sqlWriter, " "); err != nil {
+					return err
+				}
+				if err := WriteString(sqlWriter, open); err != nil {
+					return err
+				}
+			} else if err := WriteString(sqlWriter, sep); err != nil {
+				return err
+			}
+			if err := WriteString(sqlWriter, dialect.Placeholder(base+i+1)); err != nil {
+				return err
+			}
+		}
+		if err := WriteString(sqlWriter, close); err != nil {
+			return err
+		}
+	}
+	if c.Escape != "" {
+		if err := WriteKeyword(sqlWriter, " ESCAPE "); err != nil {
+			return err
+		}
+		if err := WriteString(sqlWriter, quoteString(c.Escape, DialectOf(argWriter))); err != nil {
+			return err
+		}
+	}
+	return WriteArgs(argWriter, c.Args...)
+}
@@ -2,7 +2,9 @@ package sqlbuilder_test
 
 import (
 	"bytes"
+	"database/sql"
 	"io"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -261,3 +263,78 @@ func TestBracket(t *testing.T) {
 		Expect(resArgs).To(Equal(eptArgs))
 	})
 }
+
+func TestColumnCondition(t *testing.T) {
+	t.Run("operators", func(t *testing.T) {
+		RegisterTestingT(t)
+		cases := []struct {
+			condition sqlbuilder.ColumnCondition
+			sql       string
+			args      []sqlbuilder.Arg
+		}{
+			{sqlbuilder.NewColumnCondition("a", "=", 1), "a = $2", []sqlbuilder.Arg{0, 1}},
+			{sqlbuilder.NewColumnCondition("t.a", "not like", "x%"), "t.a NOT LIKE $2", []sqlbuilder.Arg{0, "x%"}},
+			{sqlbuilder.NewColumnCondition("a", "IN", 1, 2, 3), "a IN ($2, $3, $4)", []sqlbuilder.Arg{0, 1, 2, 3}},
+			{sqlbuilder.NewColumnCondition("a", "IN"), "1 = 0", []sqlbuilder.Arg{0}},
+			{sqlbuilder.NewColumnCondition("a", "NOT IN"), "1 = 1", []sqlbuilder.Arg{0}},
+			{sqlbuilder.NewColumnCondition("a", "BETWEEN", 1, 2), "a BETWEEN $2 AND $3", []sqlbuilder.Arg{0, 1, 2}},
+			{sqlbuilder.NewColumnCondition("a", "IS NOT NULL"), "a IS NOT NULL", []sqlbuilder.Arg{0}},
+		}
+		for _, c := range cases {
+			buff := bytes.NewBufferString("")
+			argWriter := sqlbuilder.NewArgList(sqlbuilder.PostgreSQL, 0)
+			Expect(argWriter.WriteArg(0)).Should(Succeed())
+			Expect(c.condition.Parse(buff, argWriter)).Should(Succeed())
+			Expect(buff.String()).Should(Equal(c.sql))
+			Expect(argWriter.Args).Should(Equal(c.args))
+		}
+	})
+	t.Run("generic dialect", func(t *testing.T) {
+		RegisterTestingT(t)
+		buff := bytes.NewBufferString("")
+		var argWriter = NewArgWriter(0)
+		c := sqlbuilder.NewColumnCondition("a", "IN", 1, 2)
+		Expect(c.Parse(buff, argWriter)).Should(Succeed())
+		Expect(buff.String()).Should(Equal("a IN (?, ?)"))
+	})
+	t.Run("bad arguments", func(t *testing.T) {
+		RegisterTestingT(t)
+		for _, c := range []sqlbuilder.ColumnCondition{
+			sqlbuilder.NewColumnCondition("a", "="),
+			sqlbuilder.NewColumnCondition("a", "BETWEEN", 1),
+			sqlbuilder.NewColumnCondition("a", "IS NULL", 1),
+			sqlbuilder.NewColumnCondition("a", "~", 1),
+			{Column: "a", Op: "=", Args: []sqlbuilder.Arg{1}, Escape: `\`},
+			{Column: "a", Op: "LIKE", Args: []sqlbuilder.Arg{"x"}, Escape: `\\`},
+		} {
+			Expect(c.Parse(bytes.NewBufferString(""), NewArgWriter(0))).ShouldNot(Succeed())
+		}
+	})
+	t.Run("escape", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := sqlbuilder.NewColumnCondition("a", "LIKE", `x\_%`)
+		c.Escape = `\`
+		parse := func(dialect sqlbuilder.Dialect) (string, []sqlbuilder.Arg) {
+			buff := bytes.NewBufferString("")
+			argWriter := sqlbuilder.NewArgList(dialect, 0)
+			Expect(c.Parse(buff, argWriter)).Should(Succeed())
+			return buff.String(), argWriter.Args
+		}
+		where, args := parse(sqlbuilder.PostgreSQL)
+		Expect(where).Should(Equal(`a LIKE $1 ESCAPE '\'`))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{`x\_%`}))
+		where, _ = parse(sqlbuilder.MySQL)
+		Expect(where).Should(Equal(`a LIKE ? ESCAPE '\\'`))
+
+		// The escaped wildcard only matches itself.
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+		Expect(err).ShouldNot(HaveOccurred())
+		defer db.Close()
+		_, err = db.Exec("CREATE TABLE t (a TEXT); INSERT INTO t VALUES ('x_1'), ('xy1'), ('x\\_')")
+		Expect(err).ShouldNot(HaveOccurred())
+		where, args = parse(sqlbuilder.SQLite)
+		var a string
+		Expect(db.QueryRow("SELECT group_concat(a) FROM t WHERE "+where, args[0]).Scan(&a)).Should(Succeed())
+		Expect(a).Should(Equal("x_1"))
+	})
+}
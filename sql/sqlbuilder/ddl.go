@@ -0,0 +1,316 @@
+package sqlbuilder
+
+import (
+	"io"
+	"strings"
+)
+
+// The definition of a column in CREATE TABLE and ALTER TABLE.
+type ColumnDef struct {
+	Name       string
+	Type       string
+	NotNull    bool
+	PrimaryKey bool
+	Unique     bool
+	// The SQL expression of the default value, such as "0", "'a'" or "CURRENT_TIMESTAMP".
+	Default string
+	// Other constraints of the column, such as "CHECK (x > 0)" or "REFERENCES t(id)".
+	Constraints []string
+}
+
+func (c *ColumnDef) String() string {
+	var builder strings.Builder
+	_ = c.parse(&builder)
+	return builder.String()
+}
+
+// Write the definition, the keywords are written in the style of the sqlWriter.
+func (c *ColumnDef) parse(sqlWriter io.StringWriter) error {
+	if err := WriteString(sqlWriter, c.Name+" "+c.Type); err != nil {
+		return err
+	}
+	if c.NotNull {
+		if err := WriteKeyword(sqlWriter, " NOT NULL"); err != nil {
+			return err
+		}
+	}
+	if c.Default != "" {
+		if err := WriteKeyword(sqlWriter, " DEFAULT "); err != nil {
+			return err
+		}
+		if err := WriteString(sqlWriter, c.Default); err != nil {
+			return err
+		}
+	}
+	if c.PrimaryKey {
+		if err := WriteKeyword(sqlWriter, " PRIMARY KEY"); err != nil {
+			return err
+		}
+	}
+	if c.Unique {
+		if err := WriteKeyword(sqlWriter, " UNIQUE"); err != nil {
+			return err
+		}
+	}
+	for _, constraint := range c.Constraints {
+		if err := WriteString(sqlWriter, " "+constraint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write a line of the statement, the parts are keywords and names alternately, such as "DROP TABLE ", name.
+// The keywords are written in the style of the sqlWriter, and the names are written as they are.
+func writeDDLLine(sqlWriter io.StringWriter, level int, parts ...string) error {
+	err := WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	for i, part := range parts {
+		if i%2 == 0 {
+			err = WriteKeyword(sqlWriter, part)
+		} else {
+			err = WriteString(sqlWriter, part)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return EndLine(sqlWriter, CompactLevel(level))
+}
+
+// The template of CREATE TABLE statement in Data Definition Language
+type CreateTable struct {
+	Name        string
+	IfNotExists bool
+	Columns     []ColumnDef
+	// The constraints of the table, such as "PRIMARY KEY (a, b)" or "UNIQUE (c)".
+	Constraints []string
+	// The options after the definitions, such as "ENGINE=InnoDB" or "WITHOUT ROWID".
+	Options string
+}
+
+func (c *CreateTable) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	var err error
+	head := "CREATE TABLE "
+	if c.IfNotExists {
+		head += "IF NOT EXISTS "
+	}
+	err = writeDDLLine(sqlWriter, level, head, c.Name+" (")
+	if err != nil {
+		return err
+	}
+	list := newListWriter(sqlWriter, NextLevel(level), len(c.Columns)+len(c.Constraints))
+	for i := range c.Columns {
+		err = list.parse(c.Columns[i].parse)
+		if err != nil {
+			return err
+		}
+	}
+	for _, constraint := range c.Constraints {
+		err = list.item(constraint)
+		if err != nil {
+			return err
+		}
+	}
+	err = list.finish()
+	if err != nil {
+		return err
+	}
+	tail := ")"
+	if c.Options != "" {
+		tail += " " + c.Options
+	}
+	err = WriteStringWithSpace(sqlWriter, tail, level)
+	if err != nil {
+		return err
+	}
+	return EndLine(sqlWriter, CompactLevel(level))
+}
+
+// The template of DROP TABLE statement in Data Definition Language
+type DropTable struct {
+	Name     string
+	IfExists bool
+	// CASCADE is not supported by SQLite.
+	Cascade bool
+}
+
+func (c *DropTable) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	head := "DROP TABLE "
+	if c.IfExists {
+		head += "IF EXISTS "
+	}
+	if !c.Cascade {
+		return writeDDLLine(sqlWriter, level, head, c.Name)
+	}
+	if dialect := DialectOf(argWriter); dialect == SQLite {
+		return NewUnsupportedError(dialect, "DROP TABLE ... CASCADE")
+	}
+	return writeDDLLine(sqlWriter, level, head, c.Name, " CASCADE")
+}
+
+// The action of ALTER TABLE, such as AddColumn, DropColumn and RenameColumn.
+// The actions are items of a list separated by commas, so they are parsed in Compact level and should end without a space.
+type AlterAction interface {
+	Clause
+}
+
+// The template of ALTER TABLE statement in Data Definition Language
+// At least one action is required, and only one action is allowed for SQLite.
+type AlterTable struct {
+	Name    string
+	Actions []AlterAction
+}
+
+func (c *AlterTable) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	if len(c.Actions) == 0 {
+		return Locate(NewInvalidError("ALTER TABLE %s without actions", c.Name), "Actions")
+	}
+	if dialect := DialectOf(argWriter); dialect == SQLite && len(c.Actions) > 1 {
+		return NewUnsupportedError(dialect, "multiple actions in ALTER TABLE")
+	}
+	var err error
+	err = writeDDLLine(sqlWriter, level, "ALTER TABLE ", c.Name)
+	if err != nil {
+		return err
+	}
+	list := newListWriter(sqlWriter, NextLevel(level), len(c.Actions))
+	for i, action := range c.Actions {
+		err = list.parse(func(sqlWriter io.StringWriter) error {
+			return action.Parse(sqlWriter, argWriter, Compact)
+		})
+		if err != nil {
+			return LocateIndex(err, "Actions", i)
+		}
+	}
+	return list.finish()
+}
+
+type AddColumn struct {
+	Column ColumnDef
+}
+
+func (c *AddColumn) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	err := WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, "ADD COLUMN ")
+	if err != nil {
+		return err
+	}
+	return c.Column.parse(sqlWriter)
+}
+
+type DropColumn struct {
+	Name string
+}
+
+func (c *DropColumn) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	err := WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, "DROP COLUMN ")
+	if err != nil {
+		return err
+	}
+	return WriteString(sqlWriter, c.Name)
+}
+
+type RenameColumn struct {
+	Name    string
+	NewName string
+}
+
+func (c *RenameColumn) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	err := WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, "RENAME COLUMN ")
+	if err != nil {
+		return err
+	}
+	err = WriteString(sqlWriter, c.Name)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, " TO ")
+	if err != nil {
+		return err
+	}
+	return WriteString(sqlWriter, c.NewName)
+}
+
+// The template of CREATE INDEX statement in Data Definition Language
+type CreateIndex struct {
+	Name   string
+	Table  string
+	Unique bool
+	// Columns or expressions, such as "a", "b DESC" or "lower(c)".
+	Columns []string
+	// CONCURRENTLY is supported by PostgreSQL only.
+	Concurrently bool
+	// IF NOT EXISTS is not supported by MySQL.
+	IfNotExists bool
+}
+
+func (c *CreateIndex) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	dialect := DialectOf(argWriter)
+	head := "CREATE "
+	if c.Unique {
+		head += "UNIQUE "
+	}
+	head += "INDEX "
+	if c.Concurrently {
+		if dialect == MySQL || dialect == SQLite {
+			return NewUnsupportedError(dialect, "CREATE INDEX CONCURRENTLY")
+		}
+		head += "CONCURRENTLY "
+	}
+	if c.IfNotExists {
+		if dialect == MySQL {
+			return NewUnsupportedError(dialect, "CREATE INDEX IF NOT EXISTS")
+		}
+		head += "IF NOT EXISTS "
+	}
+	return writeDDLLine(sqlWriter, level, head, c.Name, " ON ", c.Table+" ("+strings.Join(c.Columns, ", ")+")")
+}
+
+// The template of DROP INDEX statement in Data Definition Language
+type DropIndex struct {
+	Name string
+	// The Table is required by MySQL, and ignored by others.
+	Table string
+	// IF EXISTS is not supported by MySQL.
+	IfExists bool
+	// CONCURRENTLY is supported by PostgreSQL only.
+	Concurrently bool
+}
+
+func (c *DropIndex) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	dialect := DialectOf(argWriter)
+	head := "DROP INDEX "
+	if c.Concurrently {
+		if dialect == MySQL || dialect == SQLite {
+			return NewUnsupportedError(dialect, "DROP INDEX CONCURRENTLY")
+		}
+		head += "CONCURRENTLY "
+	}
+	if c.IfExists {
+		if dialect == MySQL {
+			return NewUnsupportedError(dialect, "DROP INDEX IF EXISTS")
+		}
+		head += "IF EXISTS "
+	}
+	if dialect != MySQL {
+		return writeDDLLine(sqlWriter, level, head, c.Name)
+	}
+	if c.Table == "" {
+		return NewInvalidError("the table of index %s is required by %s", c.Name, dialect)
+	}
+	return writeDDLLine(sqlWriter, level, head, c.Name, " ON ", c.Table)
+}
@@ -0,0 +1,139 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestCreateTable(t *testing.T) {
+	c := &sqlbuilder.CreateTable{
+		Name:        "flows",
+		IfNotExists: true,
+		Columns: []sqlbuilder.ColumnDef{
+			{Name: "id", Type: "BIGINT", NotNull: true, PrimaryKey: true},
+			{Name: "name", Type: "VARCHAR(64)", Default: "''", Unique: true},
+			{Name: "host_id", Type: "BIGINT", Constraints: []string{"REFERENCES hosts(id)"}},
+		},
+		Constraints: []string{"CHECK (id > 0)"},
+	}
+	t.Run("with space", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args, err := sqlbuilder.Build(c, sqlbuilder.PostgreSQL, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		ept := "CREATE TABLE IF NOT EXISTS flows (\n  id BIGINT NOT NULL PRIMARY KEY,\n  name VARCHAR(64) DEFAULT '' UNIQUE,\n" +
+			"  host_id BIGINT REFERENCES hosts(id),\n  CHECK (id > 0)\n)\n"
+		Expect(sql).Should(Equal(ept))
+		Expect(args).Should(BeEmpty())
+	})
+	t.Run("without space", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := &sqlbuilder.CreateTable{
+			Name:    "t",
+			Columns: []sqlbuilder.ColumnDef{{Name: "a", Type: "INTEGER"}},
+			Options: "WITHOUT ROWID",
+		}
+		sql, _, err := sqlbuilder.Build(c, sqlbuilder.SQLite, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("CREATE TABLE t ( a INTEGER ) WITHOUT ROWID "))
+	})
+	t.Run("on error", func(t *testing.T) {
+		RegisterTestingT(t)
+		err := c.Parse(newFixedBuilder(40), NewArgWriter(0), sqlbuilder.Format)
+		Expect(err).ShouldNot(Succeed())
+	})
+}
+
+func TestDropTable(t *testing.T) {
+	RegisterTestingT(t)
+	c := &sqlbuilder.DropTable{Name: "flows", IfExists: true, Cascade: true}
+	sql, _, err := sqlbuilder.Build(c, sqlbuilder.PostgreSQL, sqlbuilder.Format)
+	Expect(err).Should(Succeed())
+	Expect(sql).Should(Equal("DROP TABLE IF EXISTS flows CASCADE\n"))
+	_, _, err = sqlbuilder.Build(c, sqlbuilder.SQLite, sqlbuilder.Format)
+	Expect(err).ShouldNot(Succeed())
+}
+
+func TestAlterTable(t *testing.T) {
+	c := &sqlbuilder.AlterTable{
+		Name: "flows",
+		Actions: []sqlbuilder.AlterAction{
+			&sqlbuilder.AddColumn{Column: sqlbuilder.ColumnDef{Name: "mtu", Type: "INT", NotNull: true, Default: "1500"}},
+			&sqlbuilder.DropColumn{Name: "legacy"},
+			&sqlbuilder.RenameColumn{Name: "src", NewName: "src_ip"},
+		},
+	}
+	t.Run("with space", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, _, err := sqlbuilder.Build(c, sqlbuilder.MySQL, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("ALTER TABLE flows\n  ADD COLUMN mtu INT NOT NULL DEFAULT 1500,\n  DROP COLUMN legacy,\n  RENAME COLUMN src TO src_ip\n"))
+	})
+	t.Run("without space", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, _, err := sqlbuilder.Build(c, sqlbuilder.PostgreSQL, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("ALTER TABLE flows ADD COLUMN mtu INT NOT NULL DEFAULT 1500, DROP COLUMN legacy, RENAME COLUMN src TO src_ip "))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, _, err := sqlbuilder.Build(c, sqlbuilder.SQLite, sqlbuilder.Format)
+		Expect(err).ShouldNot(Succeed())
+		single := &sqlbuilder.AlterTable{Name: "flows", Actions: c.Actions[2:]}
+		sql, _, err := sqlbuilder.Build(single, sqlbuilder.SQLite, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("ALTER TABLE flows\n  RENAME COLUMN src TO src_ip\n"))
+	})
+	t.Run("no actions", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, _, err := sqlbuilder.Build(&sqlbuilder.AlterTable{Name: "flows"}, sqlbuilder.PostgreSQL, sqlbuilder.Format)
+		Expect(err).Should(MatchError(sqlbuilder.ErrInvalidStructure))
+		Expect(err).Should(MatchError("AlterTable.Actions: ALTER TABLE flows without actions"))
+	})
+}
+
+func TestCreateIndex(t *testing.T) {
+	c := &sqlbuilder.CreateIndex{
+		Name:         "idx_flows_name",
+		Table:        "flows",
+		Unique:       true,
+		Columns:      []string{"name", "ts DESC"},
+		Concurrently: true,
+		IfNotExists:  true,
+	}
+	t.Run("postgresql", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, _, err := sqlbuilder.Build(c, sqlbuilder.PostgreSQL, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_flows_name ON flows (name, ts DESC)\n"))
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, _, err := sqlbuilder.Build(c, sqlbuilder.SQLite, sqlbuilder.Format)
+		Expect(err).ShouldNot(Succeed())
+		_, _, err = sqlbuilder.Build(&sqlbuilder.CreateIndex{Name: "i", Table: "t", Columns: []string{"a"}, IfNotExists: true},
+			sqlbuilder.MySQL, sqlbuilder.Format)
+		Expect(err).ShouldNot(Succeed())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := &sqlbuilder.CreateIndex{Name: "i", Table: "t", Columns: []string{"a"}, IfNotExists: true}
+		sql, _, err := sqlbuilder.Build(c, sqlbuilder.SQLite, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("CREATE INDEX IF NOT EXISTS i ON t (a) "))
+	})
+}
+
+func TestDropIndex(t *testing.T) {
+	RegisterTestingT(t)
+	sql, _, err := sqlbuilder.Build(&sqlbuilder.DropIndex{Name: "i", Table: "t"}, sqlbuilder.MySQL, sqlbuilder.Format)
+	Expect(err).Should(Succeed())
+	Expect(sql).Should(Equal("DROP INDEX i ON t\n"))
+	_, _, err = sqlbuilder.Build(&sqlbuilder.DropIndex{Name: "i"}, sqlbuilder.MySQL, sqlbuilder.Format)
+	Expect(err).ShouldNot(Succeed())
+	sql, _, err = sqlbuilder.Build(&sqlbuilder.DropIndex{Name: "i", Table: "t", IfExists: true, Concurrently: true}, sqlbuilder.PostgreSQL, sqlbuilder.Format)
+	Expect(err).Should(Succeed())
+	Expect(sql).Should(Equal("DROP INDEX CONCURRENTLY IF EXISTS i\n"))
+}
@@ -0,0 +1,182 @@
+package sqlbuilder
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The first line of SQL rendered by Debug.
+const DebugHeader = "-- DEBUG ONLY, DO NOT EXECUTE: the arguments are inlined as literals."
+
+/*
+Debug renders the clause in Format mode for debugging, the placeholders are substituted by the arguments
+as SQL literals of the dialect, so that the SQL can be copied into a database console.
+The SQL begins with DebugHeader, and must not be executed, because the escaping is not guaranteed to be safe.
+*/
+func Debug(c Clause, dialect Dialect) (string, error) {
+	var buff bytes.Buffer
+	args := NewArgList(dialect, 0)
+	if err := c.Parse(&buff, args, Format); err != nil {
+		return "", err
+	}
+	sql := buff.String()
+	var builder strings.Builder
+	builder.Grow(len(DebugHeader) + len(EOL) + len(sql))
+	builder.WriteString(DebugHeader)
+	builder.WriteString(EOL)
+	next := 0
+	for i := 0; i < len(sql); {
+		if end := skipLiteral(sql, i); end > i {
+			builder.WriteString(sql[i:end])
+			i = end
+			continue
+		}
+		index, end := -1, i+1
+		switch {
+		case dialect == PostgreSQL && sql[i] == '$':
+			for end < len(sql) && sql[end] >= '0' && sql[end] <= '9' {
+				end++
+			}
+			if end > i+1 {
+				index, _ = strconv.Atoi(sql[i+1 : end])
+				index--
+			}
+		case dialect != PostgreSQL && sql[i] == '?':
+			index = next
+			next++
+		}
+		if index < 0 {
+			builder.WriteByte(sql[i])
+			i++
+			continue
+		}
+		if index >= len(args.Args) {
+			return "", fmt.Errorf("the placeholder %s has no argument", sql[i:end])
+		}
+		literal, err := Literal(args.Args[index], dialect)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(literal)
+		i = end
+	}
+	if dialect != PostgreSQL && next != len(args.Args) {
+		return "", fmt.Errorf("%d placeholders, but %d arguments", next, len(args.Args))
+	}
+	return builder.String(), nil
+}
+
+/*
+Get the SQL literal of the argument in the dialect.
+nil and nil pointers are NULL, driver.Valuer is converted by its Value method.
+Strings are quoted, and backslashes are escaped for MySQL.
+Bytes are hex literals, such as X'ff' or '\xff'::bytea for PostgreSQL.
+Times are quoted strings, slices are lists such as (1, 2) or ARRAY[1, 2] for PostgreSQL.
+Other types are converted by their kinds as the driver binds them, such as time.Duration to an integer and net.IP to bytes,
+fmt.Stringer is used only if the kind is not supported.
+NaN and infinities are quoted for PostgreSQL, and fail for other dialects.
+*/
+func Literal(arg Arg, dialect Dialect) (string, error) {
+	if value := reflect.ValueOf(arg); value.Kind() == reflect.Pointer && value.IsNil() {
+		return "NULL", nil
+	}
+	if valuer, ok := arg.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		arg = v
+	}
+	switch v := arg.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return quoteString(v, dialect), nil
+	case []byte:
+		if dialect == PostgreSQL {
+			return `'\x` + hex.EncodeToString(v) + `'::bytea`, nil
+		}
+		return "X'" + hex.EncodeToString(v) + "'", nil
+	case time.Time:
+		if dialect == MySQL {
+			return quoteString(v.Format("2006-01-02 15:04:05.999999"), dialect), nil
+		}
+		return quoteString(v.Format("2006-01-02 15:04:05.999999999-07:00"), dialect), nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	}
+	value := reflect.ValueOf(arg)
+	switch value.Kind() {
+	case reflect.Pointer:
+		return Literal(value.Elem().Interface(), dialect)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return floatLiteral(value.Float(), value.Type().Bits(), dialect)
+	case reflect.String:
+		return quoteString(value.String(), dialect), nil
+	case reflect.Bool:
+		return Literal(value.Bool(), dialect)
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(b), value)
+			return Literal(b, dialect)
+		}
+		items := make([]string, value.Len())
+		for i := range items {
+			item, err := Literal(value.Index(i).Interface(), dialect)
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		if dialect == PostgreSQL {
+			return "ARRAY[" + strings.Join(items, ", ") + "]", nil
+		}
+		return "(" + strings.Join(items, ", ") + ")", nil
+	default:
+		if v, ok := arg.(fmt.Stringer); ok {
+			return quoteString(v.String(), dialect), nil
+		}
+		return "", fmt.Errorf("can't convert %T to literal", arg)
+	}
+}
+
+// NaN and infinities are quoted for PostgreSQL, other dialects have no literals for them.
+func floatLiteral(f float64, bits int, dialect Dialect) (string, error) {
+	if !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, bits), nil
+	}
+	if dialect != PostgreSQL {
+		return "", fmt.Errorf("can't convert %v to literal for %s", f, dialect)
+	}
+	switch {
+	case math.IsNaN(f):
+		return "'NaN'", nil
+	case f > 0:
+		return "'Infinity'", nil
+	default:
+		return "'-Infinity'", nil
+	}
+}
+
+func quoteString(s string, dialect Dialect) string {
+	s = strings.ReplaceAll(s, "'", "''")
+	if dialect == MySQL {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + s + "'"
+}
@@ -0,0 +1,107 @@
+package sqlbuilder_test
+
+import (
+	"database/sql"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestDebug(t *testing.T) {
+	t.Run("render", func(t *testing.T) {
+		RegisterTestingT(t)
+		q := sqlbuilder.NewQuery("flows").
+			Select("id", "'?' AS mark -- $1 ?").
+			Where(sqlbuilder.NewColumnCondition("name", "=", "it's")).
+			Where(sqlbuilder.NewColumnCondition("id", "IN", 1, 2)).
+			Limit("10")
+		sql, err := sqlbuilder.Debug(q, sqlbuilder.PostgreSQL)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal(sqlbuilder.DebugHeader + `
+SELECT
+  id,
+  '?' AS mark -- $1 ?
+FROM flows
+WHERE
+  name = 'it''s'
+  AND id IN (1, 2)
+LIMIT 10
+`))
+		sql, err = sqlbuilder.Debug(q, sqlbuilder.MySQL)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(ContainSubstring("  name = 'it''s'\n  AND id IN (1, 2)\nLIMIT 10\n"))
+	})
+	t.Run("mismatched", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, err := sqlbuilder.Debug(sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "SELECT ?, ?", 1), sqlbuilder.SQLite)
+		Expect(err).Should(MatchError("the placeholder ? has no argument"))
+		_, err = sqlbuilder.Debug(sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "SELECT 1", 1), sqlbuilder.SQLite)
+		Expect(err).Should(MatchError("0 placeholders, but 1 arguments"))
+	})
+}
+
+type debugEnum int
+
+func (e debugEnum) String() string {
+	return "enum"
+}
+
+type debugStringer struct{}
+
+func (debugStringer) String() string {
+	return "stringer"
+}
+
+func TestLiteral(t *testing.T) {
+	RegisterTestingT(t)
+	var nilPtr *int
+	n := 3
+	ts := time.Date(2024, 5, 1, 10, 20, 30, 500000000, time.UTC)
+	cases := []struct {
+		arg      sqlbuilder.Arg
+		dialect  sqlbuilder.Dialect
+		expected string
+	}{
+		{nil, sqlbuilder.Generic, "NULL"},
+		{nilPtr, sqlbuilder.Generic, "NULL"},
+		{&n, sqlbuilder.Generic, "3"},
+		{int8(-1), sqlbuilder.Generic, "-1"},
+		{uint64(18446744073709551615), sqlbuilder.Generic, "18446744073709551615"},
+		{1.5, sqlbuilder.Generic, "1.5"},
+		{float32(0.1), sqlbuilder.Generic, "0.1"},
+		{true, sqlbuilder.PostgreSQL, "TRUE"},
+		{false, sqlbuilder.SQLite, "FALSE"},
+		{`a'b\c`, sqlbuilder.PostgreSQL, `'a''b\c'`},
+		{`a'b\c`, sqlbuilder.MySQL, `'a''b\\c'`},
+		{[]byte{0xde, 0xad}, sqlbuilder.PostgreSQL, `'\xdead'::bytea`},
+		{[]byte{0xde, 0xad}, sqlbuilder.MySQL, "X'dead'"},
+		{[2]byte{0xbe, 0xef}, sqlbuilder.SQLite, "X'beef'"},
+		{ts, sqlbuilder.PostgreSQL, "'2024-05-01 10:20:30.5+00:00'"},
+		{ts, sqlbuilder.MySQL, "'2024-05-01 10:20:30.5'"},
+		{[]string{"a", "b"}, sqlbuilder.PostgreSQL, "ARRAY['a', 'b']"},
+		{[]int{1, 2}, sqlbuilder.SQLite, "(1, 2)"},
+		{sql.NullString{}, sqlbuilder.Generic, "NULL"},
+		{sql.NullInt64{Int64: 7, Valid: true}, sqlbuilder.Generic, "7"},
+		{time.Second, sqlbuilder.Generic, "1000000000"},
+		{debugEnum(2), sqlbuilder.Generic, "2"},
+		{net.IPv4(10, 0, 0, 1).To4(), sqlbuilder.SQLite, "X'0a000001'"},
+		{debugStringer{}, sqlbuilder.Generic, "'stringer'"},
+		{math.NaN(), sqlbuilder.PostgreSQL, "'NaN'"},
+		{math.Inf(1), sqlbuilder.PostgreSQL, "'Infinity'"},
+		{float32(math.Inf(-1)), sqlbuilder.PostgreSQL, "'-Infinity'"},
+	}
+	for _, c := range cases {
+		literal, err := sqlbuilder.Literal(c.arg, c.dialect)
+		Expect(err).Should(Succeed())
+		Expect(literal).Should(Equal(c.expected), "%#v", c.arg)
+	}
+	_, err := sqlbuilder.Literal(map[string]int{}, sqlbuilder.Generic)
+	Expect(err).Should(MatchError("can't convert map[string]int to literal"))
+	_, err = sqlbuilder.Literal(math.Inf(1), sqlbuilder.MySQL)
+	Expect(err).Should(MatchError("can't convert +Inf to literal for mysql"))
+}
@@ -0,0 +1,132 @@
+package sqlbuilder
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// Dialect describes the database which the SQL will be executed on.
+// Clauses which are different between databases will be parsed according to it.
+type Dialect int
+
+const (
+	Generic    Dialect = iota // The placeholder is "?", and nothing is database specific.
+	MySQL                     // The placeholder is "?".
+	PostgreSQL                // The placeholder is "$n".
+	SQLite                    // The placeholder is "?".
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case Generic:
+		return "generic"
+	case MySQL:
+		return "mysql"
+	case PostgreSQL:
+		return "postgresql"
+	case SQLite:
+		return "sqlite"
+	default:
+		return "dialect(" + strconv.Itoa(int(d)) + ")"
+	}
+}
+
+// The placeholders of PostgreSQL are cached to avoid allocations.
+var postgresqlPlaceholders = func() []string {
+	res := make([]string, 256)
+	for i := range res {
+		res[i] = "$" + strconv.Itoa(i)
+	}
+	return res
+}()
+
+// Get the placeholder of the index-th argument, the index starts from 1.
+func (d Dialect) Placeholder(index int) string {
+	if d == PostgreSQL {
+		if index >= 0 && index < len(postgresqlPlaceholders) {
+			return postgresqlPlaceholders[index]
+		}
+		return "$" + strconv.Itoa(index)
+	}
+	return "?"
+}
+
+// An ArgWriter implemented DialectArgWriter carries the dialect when parsing the SQL.
+// The ArgWriter which is not a DialectArgWriter will be treated as Generic.
+type DialectArgWriter interface {
+	ArgWriter
+	Dialect() Dialect
+	// The number of arguments which have been written.
+	Len() int
+}
+
+// Get the dialect carried by the argWriter.
+func DialectOf(argWriter ArgWriter) Dialect {
+	if w, ok := argWriter.(DialectArgWriter); ok {
+		return w.Dialect()
+	}
+	return Generic
+}
+
+// Get the placeholder of the next argument will be written into the argWriter.
+func NextPlaceholder(argWriter ArgWriter) string {
+	if w, ok := argWriter.(DialectArgWriter); ok {
+		return w.Dialect().Placeholder(w.Len() + 1)
+	}
+	return Generic.Placeholder(1)
+}
+
+// Get the placeholders of the next n arguments will be written into the argWriter.
+func NextPlaceholders(argWriter ArgWriter, n int) []string {
+	dialect, base := placeholderBase(argWriter)
+	res := make([]string, n)
+	for i := range res {
+		res[i] = dialect.Placeholder(base + i + 1)
+	}
+	return res
+}
+
+// Get the dialect and the number of arguments written into the argWriter.
+func placeholderBase(argWriter ArgWriter) (Dialect, int) {
+	if w, ok := argWriter.(DialectArgWriter); ok {
+		return w.Dialect(), w.Len()
+	}
+	return Generic, 0
+}
+
+// ArgList is a DialectArgWriter which collects the arguments in order.
+type ArgList struct {
+	dialect Dialect
+	Args    []Arg
+}
+
+func NewArgList(dialect Dialect, cap int) *ArgList {
+	return &ArgList{
+		dialect: dialect,
+		Args:    make([]Arg, 0, cap),
+	}
+}
+
+func (l *ArgList) WriteArg(arg Arg) error {
+	l.Args = append(l.Args, arg)
+	return nil
+}
+
+func (l *ArgList) Dialect() Dialect {
+	return l.dialect
+}
+
+func (l *ArgList) Len() int {
+	return len(l.Args)
+}
+
+// Build the clause for the dialect, returns the SQL and its arguments.
+// The error is a *ParseError located from the type of the clause.
+func Build(c Clause, dialect Dialect, level int) (string, []Arg, error) {
+	var buff bytes.Buffer
+	args := NewArgList(dialect, 0)
+	if err := c.Parse(&buff, args, level); err != nil {
+		return "", nil, locateRoot(err, c)
+	}
+	return buff.String(), args.Args, nil
+}
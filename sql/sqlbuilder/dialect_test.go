@@ -0,0 +1,49 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestDialect(t *testing.T) {
+	t.Run("placeholder", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(sqlbuilder.Generic.Placeholder(2)).Should(Equal("?"))
+		Expect(sqlbuilder.MySQL.Placeholder(2)).Should(Equal("?"))
+		Expect(sqlbuilder.SQLite.Placeholder(2)).Should(Equal("?"))
+		Expect(sqlbuilder.PostgreSQL.Placeholder(2)).Should(Equal("$2"))
+	})
+	t.Run("dialect of arg writer", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(sqlbuilder.DialectOf(NewArgWriter(0))).Should(Equal(sqlbuilder.Generic))
+		Expect(sqlbuilder.DialectOf(nil)).Should(Equal(sqlbuilder.Generic))
+		Expect(sqlbuilder.DialectOf(sqlbuilder.NewArgList(sqlbuilder.PostgreSQL, 0))).Should(Equal(sqlbuilder.PostgreSQL))
+	})
+	t.Run("next placeholder", func(t *testing.T) {
+		RegisterTestingT(t)
+		args := sqlbuilder.NewArgList(sqlbuilder.PostgreSQL, 0)
+		Expect(sqlbuilder.NextPlaceholder(args)).Should(Equal("$1"))
+		Expect(args.WriteArg(1)).Should(Succeed())
+		Expect(sqlbuilder.NextPlaceholder(args)).Should(Equal("$2"))
+		Expect(sqlbuilder.NextPlaceholder(NewArgWriter(0))).Should(Equal("?"))
+	})
+	t.Run("build", func(t *testing.T) {
+		RegisterTestingT(t)
+		dql := sqlbuilder.DQL{
+			From: sqlbuilder.FromTableName("demo_table"),
+			Where: sqlbuilder.WhereClause{
+				Conditions: []sqlbuilder.Condition{
+					sqlbuilder.NewNamedCondition("x >= :x", map[string]any{"x": 1}),
+					sqlbuilder.NewNamedCondition("y != :y", map[string]any{"y": "2"}),
+				},
+			},
+		}
+		sql, args, err := sqlbuilder.Build(&dql, sqlbuilder.PostgreSQL, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT * FROM demo_table WHERE x >= $1 AND y != $2 "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{1, "2"}))
+	})
+}
@@ -0,0 +1,119 @@
+package sqlbuilder
+
+import (
+	"io"
+)
+
+// The template of UPDATE statement in Data Manipulation Language
+type Update struct {
+	With       With
+	Table      string
+	Set        Set
+	Where      WhereClause
+	Additional Clauses
+}
+
+func (l *Update) Clauses() Clauses {
+	cs := make([]Clause, 0, 4+len(l.Additional))
+	if l.With != nil {
+		cs = append(cs, l.With)
+	}
+	cs = append(cs, NewKeywordClause(AutoNewline, "UPDATE", l.Table), &l.Set)
+	if l.Where.Valid() {
+		cs = append(cs, l.Where)
+	}
+	return appendClauses(cs, l.Additional)
+}
+
+func (l *Update) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	var err error
+	if l.With != nil {
+		if err = l.With.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "With")
+		}
+	}
+	if err = NewKeywordClause(AutoNewline, "UPDATE", l.Table).Parse(sqlWriter, argWriter, level); err != nil {
+		return err
+	}
+	if err = l.Set.Parse(sqlWriter, argWriter, level); err != nil {
+		return Locate(err, "Set")
+	}
+	if l.Where.Valid() {
+		if err = l.Where.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "Where")
+		}
+	}
+	return Locate(l.Additional.Parse(sqlWriter, argWriter, level), "Additional")
+}
+
+type Set struct {
+	// Such as "a = ?", "b = b + 1".
+	Assignments []string
+	Args        []Arg
+}
+
+func (c *Set) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	var err error
+	err = WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, "SET")
+	if err != nil {
+		return err
+	}
+	err = EndLine(sqlWriter, CompactLevel(level))
+	if err != nil {
+		return err
+	}
+	list := newListWriter(sqlWriter, NextLevel(level), len(c.Assignments))
+	for _, assignment := range c.Assignments {
+		err = list.item(assignment)
+		if err != nil {
+			return err
+		}
+	}
+	err = list.finish()
+	if err != nil {
+		return err
+	}
+	return WriteArgs(argWriter, c.Args...)
+}
+
+// The template of DELETE statement in Data Manipulation Language
+type Delete struct {
+	With       With
+	Table      string
+	Where      WhereClause
+	Additional Clauses
+}
+
+func (l *Delete) Clauses() Clauses {
+	cs := make([]Clause, 0, 3+len(l.Additional))
+	if l.With != nil {
+		cs = append(cs, l.With)
+	}
+	cs = append(cs, NewKeywordClause(AutoNewline, "DELETE FROM", l.Table))
+	if l.Where.Valid() {
+		cs = append(cs, l.Where)
+	}
+	return appendClauses(cs, l.Additional)
+}
+
+func (l *Delete) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	var err error
+	if l.With != nil {
+		if err = l.With.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "With")
+		}
+	}
+	if err = NewKeywordClause(AutoNewline, "DELETE FROM", l.Table).Parse(sqlWriter, argWriter, level); err != nil {
+		return err
+	}
+	if l.Where.Valid() {
+		if err = l.Where.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "Where")
+		}
+	}
+	return Locate(l.Additional.Parse(sqlWriter, argWriter, level), "Additional")
+}
@@ -0,0 +1,87 @@
+package sqlbuilder_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestUpdate(t *testing.T) {
+	newUpdate := func() *sqlbuilder.Update {
+		return &sqlbuilder.Update{
+			Table: "demo_table",
+			Set: sqlbuilder.Set{
+				Assignments: []string{"x = ?", "y = y + 1"},
+				Args:        []sqlbuilder.Arg{1},
+			},
+			Where: sqlbuilder.WhereClause{
+				Conditions: []sqlbuilder.Condition{
+					sqlbuilder.NewCondition("z = ?", "2"),
+				},
+			},
+			Additional: []sqlbuilder.Clause{
+				sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "RETURNING x"),
+			},
+		}
+	}
+	t.Run("with space", func(t *testing.T) {
+		RegisterTestingT(t)
+		buff := bytes.NewBufferString("")
+		var argWriter = NewArgWriter(0)
+		err := newUpdate().Parse(buff, argWriter, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		ept := "UPDATE demo_table\nSET\n  x = ?,\n  y = y + 1\nWHERE\n  z = ?\nRETURNING x\n"
+		Expect(buff.String()).To(Equal(ept))
+		Expect(argWriter.Args).To(Equal([]sqlbuilder.Arg{1, "2"}))
+	})
+	t.Run("without space", func(t *testing.T) {
+		RegisterTestingT(t)
+		buff := bytes.NewBufferString("")
+		err := newUpdate().Parse(buff, NewArgWriter(0), sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(buff.String()).To(Equal("UPDATE demo_table SET x = ?, y = y + 1 WHERE z = ? RETURNING x "))
+	})
+	t.Run("on error", func(t *testing.T) {
+		RegisterTestingT(t)
+		err := newUpdate().Parse(newFixedBuilder(20), NewArgWriter(0), sqlbuilder.Format)
+		Expect(err).ShouldNot(Succeed())
+	})
+}
+
+func TestDelete(t *testing.T) {
+	t.Run("with space", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := sqlbuilder.Delete{
+			With: &sqlbuilder.WithClause{
+				Tables: []sqlbuilder.Table{
+					sqlbuilder.NameAsTable("a", sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "SELECT id FROM demo.A")),
+				},
+			},
+			Table: "demo_table",
+			Where: sqlbuilder.WhereClause{
+				Conditions: []sqlbuilder.Condition{
+					sqlbuilder.NewCondition("id IN (SELECT id FROM a)"),
+					sqlbuilder.NewCondition("z = ?", 1),
+				},
+			},
+		}
+		buff := bytes.NewBufferString("")
+		var argWriter = NewArgWriter(0)
+		err := c.Parse(buff, argWriter, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		ept := "WITH\na AS (\n  SELECT id FROM demo.A\n)\nDELETE FROM demo_table\nWHERE\n  id IN (SELECT id FROM a)\n  AND z = ?\n"
+		Expect(buff.String()).To(Equal(ept))
+		Expect(argWriter.Args).To(Equal([]sqlbuilder.Arg{1}))
+	})
+	t.Run("without where", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := sqlbuilder.Delete{Table: "demo_table"}
+		buff := bytes.NewBufferString("")
+		err := c.Parse(buff, nil, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(buff.String()).To(Equal("DELETE FROM demo_table "))
+	})
+}
@@ -1,6 +1,7 @@
 package sqlbuilder
 
 import (
+	"fmt"
 	"io"
 )
 
@@ -10,11 +11,13 @@ type DQL struct {
 	With       With
 	Select     Select
 	From       From
+	Joins      []Join
 	Where      WhereClause
 	Group      Group
 	Having     HavingClause
 	Order      Order
 	Limit      Limit
+	Lock       Lock
 	Additional Clauses
 }
 
@@ -26,18 +29,25 @@ func (l *DQL) Clauses() Clauses {
 	if l.From.Valid() {
 		count++
 	}
+	count += len(l.Joins)
 	if l.Where.Valid() {
 		count++
 	}
 	if l.Group != nil {
 		count++
 	}
+	if l.Having.Valid() {
+		count++
+	}
 	if l.Order != nil {
 		count++
 	}
 	if l.Limit != nil {
 		count++
 	}
+	if l.Lock.Valid() {
+		count++
+	}
 	count += len(l.Additional)
 	cs := make([]Clause, 0, count)
 	if l.With != nil {
@@ -47,6 +57,9 @@ func (l *DQL) Clauses() Clauses {
 	if l.From.Valid() {
 		cs = append(cs, l.From)
 	}
+	for i := range l.Joins {
+		cs = append(cs, &l.Joins[i])
+	}
 	if l.Where.Valid() {
 		cs = append(cs, l.Where)
 	}
@@ -62,48 +75,53 @@ func (l *DQL) Clauses() Clauses {
 	if l.Limit != nil {
 		cs = append(cs, l.Limit)
 	}
-	if l.Additional != nil {
-		cs = append(cs, l.Additional...)
+	if l.Lock.Valid() {
+		cs = append(cs, l.Lock)
 	}
-	return cs
+	return appendClauses(cs, l.Additional)
 }
 
+// The nil conditions are skipped, and nothing is written if all conditions are nil.
 func buildConditions(name string, conditions []Condition, sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
 	var err error
-	if len(conditions) > 0 {
-		err = WriteStringWithSpace(sqlWriter, name, level)
-		if err != nil {
-			return err
+	first := true
+	for i, c := range conditions {
+		if c == nil {
+			continue
 		}
-		err = EndLine(sqlWriter, CompactLevel(level))
+		if first {
+			err = WriteSpace(sqlWriter, level)
+			if err != nil {
+				return err
+			}
+			err = WriteKeyword(sqlWriter, name)
+			if err != nil {
+				return err
+			}
+			err = EndLine(sqlWriter, CompactLevel(level))
+			if err != nil {
+				return err
+			}
+		}
+		err = WriteSpace(sqlWriter, NextLevel(level))
 		if err != nil {
 			return err
 		}
-		for i, c := range conditions {
-			if i != 0 {
-				err = WriteStringWithSpace(sqlWriter, "AND ", NextLevel(level))
-				if err != nil {
-					return err
-				}
-				err = c.Parse(sqlWriter, argWriter)
-				if err != nil {
-					return err
-				}
-			} else {
-				err = WriteSpace(sqlWriter, NextLevel(level))
-				if err != nil {
-					return err
-				}
-				err = c.Parse(sqlWriter, argWriter)
-				if err != nil {
-					return err
-				}
-			}
-			err = EndLine(sqlWriter, CompactLevel(level))
+		if !first {
+			err = WriteKeyword(sqlWriter, "AND ")
 			if err != nil {
 				return err
 			}
 		}
+		first = false
+		err = c.Parse(sqlWriter, argWriter)
+		if err != nil {
+			return LocateIndex(err, "Conditions", i)
+		}
+		err = EndLine(sqlWriter, CompactLevel(level))
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -132,59 +150,109 @@ func (c HavingClause) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, leve
 	return buildConditions("HAVING", c.Conditions, sqlWriter, argWriter, level)
 }
 
+// Parse the clauses in the same order as Clauses, but without allocating the slice, keep them in sync.
 func (l *DQL) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
-	cs := l.Clauses()
-	return cs.Parse(sqlWriter, argWriter, level)
+	var err error
+	if l.With != nil {
+		if err = l.With.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "With")
+		}
+	}
+	if err = l.Select.Parse(sqlWriter, argWriter, level); err != nil {
+		return Locate(err, "Select")
+	}
+	if l.From.Valid() {
+		if err = l.From.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "From")
+		}
+	}
+	for i := range l.Joins {
+		if err = l.Joins[i].Parse(sqlWriter, argWriter, level); err != nil {
+			return LocateIndex(err, "Joins", i)
+		}
+	}
+	if l.Where.Valid() {
+		if err = l.Where.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "Where")
+		}
+	}
+	if l.Group != nil {
+		if err = l.Group.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "Group")
+		}
+	}
+	if l.Having.Valid() {
+		if err = l.Having.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "Having")
+		}
+	}
+	if l.Order != nil {
+		if err = l.Order.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "Order")
+		}
+	}
+	if l.Limit != nil {
+		if err = l.Limit.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "Limit")
+		}
+	}
+	if l.Lock.Valid() {
+		if err = l.Lock.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "Lock")
+		}
+	}
+	return Locate(l.Additional.Parse(sqlWriter, argWriter, level), "Additional")
 }
 
 type Select struct {
 	Columns []string
 	// Args are valid if the Columns is not a empty slice or nil.
 	Args []Arg
+	// Expressions are selected after the Columns, such as expr.As(expr.CountAll(), "total").
+	Expressions []Expression
 }
 
 func (c *Select) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
 	var err error
-	if len(c.Columns) == 0 {
-		err = WriteStringWithSpace(sqlWriter, "SELECT *", level)
-		if err != nil {
-			return err
-		}
-		err = EndLine(sqlWriter, CompactLevel(level))
-		if err != nil {
-			return err
-		}
-	} else {
-		err = WriteStringWithSpace(sqlWriter, "SELECT", level)
+	err = WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	if len(c.Columns) == 0 && len(c.Expressions) == 0 {
+		err = WriteKeyword(sqlWriter, "SELECT *")
 		if err != nil {
 			return err
 		}
-		err = EndLine(sqlWriter, CompactLevel(level))
+		return EndLine(sqlWriter, CompactLevel(level))
+	}
+	err = WriteKeyword(sqlWriter, "SELECT")
+	if err != nil {
+		return err
+	}
+	err = EndLine(sqlWriter, CompactLevel(level))
+	if err != nil {
+		return err
+	}
+	list := newListWriter(sqlWriter, NextLevel(level), len(c.Columns)+len(c.Expressions))
+	for _, col := range c.Columns {
+		err = list.item(col)
 		if err != nil {
 			return err
 		}
-		for i, col := range c.Columns {
-			err = WriteStringWithSpace(sqlWriter, col, NextLevel(level))
-			if err != nil {
-				return err
-			}
-			if i != len(c.Columns)-1 {
-				err = WriteString(sqlWriter, ",")
-				if err != nil {
-					return err
-				}
-			}
-			err = EndLine(sqlWriter, CompactLevel(level))
-			if err != nil {
-				return err
-			}
-		}
-		err = WriteArgs(argWriter, c.Args...)
+	}
+	err = WriteArgs(argWriter, c.Args...)
+	if err != nil {
+		return err
+	}
+	for i, e := range c.Expressions {
+		err = list.parse(func(sqlWriter io.StringWriter) error {
+			return e.Parse(sqlWriter, argWriter)
+		})
 		if err != nil {
-			return err
+			return LocateIndex(err, "Expressions", i)
 		}
 	}
-	return nil
+	return list.finish()
 }
 
 type NamePosition int
@@ -256,7 +324,7 @@ func (c From) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) e
 		case NameAfter:
 			return ParseNameAfter(c.Table.Name, c.Table.Clause, sqlWriter, argWriter, level)
 		default:
-			panic("bad NamePosition")
+			return NewInvalidError("bad NamePosition %d", c.Table.NamePosition)
 		}
 	}
 	if c.Table.Clause != nil {
@@ -270,7 +338,11 @@ func (c From) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) e
 
 func ParseTableName(name string, sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
 	var err error
-	err = WriteStringWithSpace(sqlWriter, "FROM ", level)
+	err = WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, "FROM ")
 	if err != nil {
 		return err
 	}
@@ -287,7 +359,11 @@ func ParseTableName(name string, sqlWriter io.StringWriter, argWriter ArgWriter,
 
 func ParseSubTable(table Clause, sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
 	var err error
-	err = WriteStringWithSpace(sqlWriter, "FROM (", level)
+	err = WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, "FROM (")
 	if err != nil {
 		return err
 	}
@@ -297,7 +373,7 @@ func ParseSubTable(table Clause, sqlWriter io.StringWriter, argWriter ArgWriter,
 	}
 	err = table.Parse(sqlWriter, argWriter, NextLevel(level))
 	if err != nil {
-		return err
+		return Locate(err, "Table.Clause")
 	}
 	err = WriteStringWithSpace(sqlWriter, ")", level)
 	if err != nil {
@@ -312,7 +388,11 @@ func ParseSubTable(table Clause, sqlWriter io.StringWriter, argWriter ArgWriter,
 
 func ParseNameFirst(name string, clause Clause, sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
 	var err error
-	err = WriteStringWithSpace(sqlWriter, "FROM ", level)
+	err = WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, "FROM ")
 	if err != nil {
 		return err
 	}
@@ -320,7 +400,7 @@ func ParseNameFirst(name string, clause Clause, sqlWriter io.StringWriter, argWr
 	if err != nil {
 		return err
 	}
-	err = WriteString(sqlWriter, " AS (")
+	err = WriteKeyword(sqlWriter, " AS (")
 	if err != nil {
 		return err
 	}
@@ -330,7 +410,7 @@ func ParseNameFirst(name string, clause Clause, sqlWriter io.StringWriter, argWr
 	}
 	err = clause.Parse(sqlWriter, argWriter, NextLevel(level))
 	if err != nil {
-		return err
+		return Locate(err, "Table.Clause")
 	}
 	err = WriteString(sqlWriter, ")")
 	if err != nil {
@@ -345,7 +425,11 @@ func ParseNameFirst(name string, clause Clause, sqlWriter io.StringWriter, argWr
 
 func ParseNameAfter(name string, clause Clause, sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
 	var err error
-	err = WriteStringWithSpace(sqlWriter, "FROM (", level)
+	err = WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, "FROM (")
 	if err != nil {
 		return err
 	}
@@ -355,9 +439,9 @@ func ParseNameAfter(name string, clause Clause, sqlWriter io.StringWriter, argWr
 	}
 	err = clause.Parse(sqlWriter, argWriter, NextLevel(level))
 	if err != nil {
-		return err
+		return Locate(err, "Table.Clause")
 	}
-	err = WriteString(sqlWriter, ") AS ")
+	err = WriteKeyword(sqlWriter, ") AS ")
 	if err != nil {
 		return err
 	}
@@ -372,6 +456,91 @@ func ParseNameAfter(name string, clause Clause, sqlWriter io.StringWriter, argWr
 	return nil
 }
 
+// The JOIN clause after FROM, such as "LEFT JOIN b ON a.id = b.a_id".
+type Join struct {
+	// Such as "JOIN", "LEFT JOIN" and "CROSS JOIN".
+	Kind string
+	// The sub query must be named after it, such as TableAsName(clause, "b").
+	Table Table
+	// The conditions are joined with AND, the nil conditions are skipped, and ON is omitted if all of them are nil.
+	On []Condition
+}
+
+func (c *Join) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	var err error
+	err = WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, c.Kind)
+	if err != nil {
+		return err
+	}
+	if c.Table.Clause == nil {
+		err = WriteString(sqlWriter, Space)
+		if err != nil {
+			return err
+		}
+		err = WriteString(sqlWriter, c.Table.Name)
+		if err != nil {
+			return err
+		}
+	} else {
+		switch {
+		case c.Table.Name == "":
+			return NewInvalidError("sub query of %s without name", c.Kind)
+		case c.Table.NamePosition == NameFirst:
+			return NewInvalidError("NameFirst is not supported by %s, the sub query is named after it", c.Kind)
+		case c.Table.NamePosition != NameAfter:
+			return NewInvalidError("bad NamePosition %d", c.Table.NamePosition)
+		}
+		err = WriteString(sqlWriter, " (")
+		if err != nil {
+			return err
+		}
+		err = EndLine(sqlWriter, CompactLevel(level))
+		if err != nil {
+			return err
+		}
+		err = c.Table.Clause.Parse(sqlWriter, argWriter, NextLevel(level))
+		if err != nil {
+			return Locate(err, "Table.Clause")
+		}
+		err = WriteSpace(sqlWriter, level)
+		if err != nil {
+			return err
+		}
+		err = WriteKeyword(sqlWriter, ") AS ")
+		if err != nil {
+			return err
+		}
+		err = WriteString(sqlWriter, c.Table.Name)
+		if err != nil {
+			return err
+		}
+	}
+	first := true
+	for i, on := range c.On {
+		if on == nil {
+			continue
+		}
+		if first {
+			err = WriteKeyword(sqlWriter, " ON ")
+		} else {
+			err = WriteKeyword(sqlWriter, " AND ")
+		}
+		if err != nil {
+			return err
+		}
+		first = false
+		err = on.Parse(sqlWriter, argWriter)
+		if err != nil {
+			return LocateIndex(err, "On", i)
+		}
+	}
+	return EndLine(sqlWriter, CompactLevel(level))
+}
+
 type With interface {
 	Clause
 }
@@ -386,7 +555,7 @@ func (c *WithClause) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level
 		level = 0
 	}
 	var err error
-	err = WriteString(sqlWriter, "WITH")
+	err = WriteKeyword(sqlWriter, "WITH")
 	if err != nil {
 		return err
 	}
@@ -402,7 +571,7 @@ func (c *WithClause) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level
 				if err != nil {
 					return err
 				}
-				err = WriteString(sqlWriter, " AS (")
+				err = WriteKeyword(sqlWriter, " AS (")
 				if err != nil {
 					return err
 				}
@@ -412,7 +581,7 @@ func (c *WithClause) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level
 				}
 				err = t.Clause.Parse(sqlWriter, argWriter, NextLevel(level))
 				if err != nil {
-					return err
+					return Locate(err, fmt.Sprintf("Tables[%d].Clause", i))
 				}
 				err = WriteString(sqlWriter, ")")
 				if err != nil {
@@ -429,9 +598,9 @@ func (c *WithClause) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level
 				}
 				err = t.Clause.Parse(sqlWriter, argWriter, 1)
 				if err != nil {
-					return err
+					return Locate(err, fmt.Sprintf("Tables[%d].Clause", i))
 				}
-				err = WriteString(sqlWriter, ") AS ")
+				err = WriteKeyword(sqlWriter, ") AS ")
 				if err != nil {
 					return err
 				}
@@ -440,7 +609,7 @@ func (c *WithClause) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level
 					return err
 				}
 			default:
-				panic("bad NamePosition")
+				return LocateIndex(NewInvalidError("bad NamePosition %d", t.NamePosition), "Tables", i)
 			}
 		} else {
 			err = WriteString(sqlWriter, t.Name)
@@ -467,7 +636,7 @@ type Group interface {
 }
 
 func MakeGroupby(value string, args ...Arg) Group {
-	return NewSimpleClause(AutoNewline, "GROUP BY "+value, args...)
+	return NewKeywordClause(AutoNewline, "GROUP BY", value, args...)
 }
 
 type Order interface {
@@ -475,7 +644,7 @@ type Order interface {
 }
 
 func MakeOrderby(value string, args ...Arg) Order {
-	return NewSimpleClause(AutoNewline, "ORDER BY "+value, args...)
+	return NewKeywordClause(AutoNewline, "ORDER BY", value, args...)
 }
 
 type Limit interface {
@@ -483,5 +652,5 @@ type Limit interface {
 }
 
 func MakeLimit(value string, args ...Arg) Order {
-	return NewSimpleClause(AutoNewline, "LIMIT "+value, args...)
+	return NewKeywordClause(AutoNewline, "LIMIT", value, args...)
 }
@@ -300,3 +300,91 @@ func TestDQL(t *testing.T) {
 		Expect(res).To(Equal(ept))
 	})
 }
+
+func TestJoin(t *testing.T) {
+	t.Run("with space", func(t *testing.T) {
+		RegisterTestingT(t)
+		dql := sqlbuilder.DQL{
+			From: sqlbuilder.FromTableName("a"),
+			Joins: []sqlbuilder.Join{
+				{Kind: "JOIN", Table: sqlbuilder.TableByName("b"), On: []sqlbuilder.Condition{
+					sqlbuilder.NewCondition("a.id = b.a_id"),
+					sqlbuilder.NewCondition("b.x = ?", 1),
+				}},
+				{Kind: "LEFT JOIN", Table: sqlbuilder.TableAsName(&sqlbuilder.DQL{From: sqlbuilder.FromTableName("c")}, "cc"), On: []sqlbuilder.Condition{
+					sqlbuilder.NewCondition("a.id = cc.a_id"),
+				}},
+				{Kind: "CROSS JOIN", Table: sqlbuilder.TableByName("d")},
+			},
+			Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewCondition("a.y = ?", 2)}},
+		}
+		buff := bytes.NewBufferString("")
+		var argWriter = NewArgWriter(0)
+		err := dql.Parse(buff, argWriter, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		ept := "SELECT *\nFROM a\nJOIN b ON a.id = b.a_id AND b.x = ?\nLEFT JOIN (\n  SELECT *\n  FROM c\n) AS cc ON a.id = cc.a_id\nCROSS JOIN d\nWHERE\n  a.y = ?\n"
+		Expect(buff.String()).To(Equal(ept))
+		Expect(argWriter.Args).To(Equal([]sqlbuilder.Arg{1, 2}))
+
+		buff = bytes.NewBufferString("")
+		err = dql.Parse(buff, NewArgWriter(0), sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(buff.String()).To(Equal("SELECT * FROM a JOIN b ON a.id = b.a_id AND b.x = ? LEFT JOIN ( SELECT * FROM c ) AS cc ON a.id = cc.a_id CROSS JOIN d WHERE a.y = ? "))
+	})
+	t.Run("invalid sub query", func(t *testing.T) {
+		RegisterTestingT(t)
+		sub := &sqlbuilder.DQL{From: sqlbuilder.FromTableName("c")}
+		for _, table := range []sqlbuilder.Table{
+			sqlbuilder.TableByClause(sub),
+			sqlbuilder.NameAsTable("cc", sub),
+			{Clause: sub, Name: "cc", NamePosition: 2},
+		} {
+			dql := sqlbuilder.DQL{
+				From:  sqlbuilder.FromTableName("a"),
+				Joins: []sqlbuilder.Join{{Kind: "JOIN", Table: table}},
+			}
+			_, _, err := sqlbuilder.Build(&dql, sqlbuilder.Generic, sqlbuilder.Compact)
+			Expect(err).Should(MatchError(sqlbuilder.ErrInvalidStructure))
+			Expect(err).Should(MatchError(ContainSubstring("DQL.Joins[0]")))
+			Expect(dql.Validate()).ShouldNot(Succeed())
+		}
+	})
+}
+
+// DQL.Parse keeps its own copy of the order of Clauses to avoid allocations, they must produce the same output.
+func TestDQLClausesOrder(t *testing.T) {
+	RegisterTestingT(t)
+	dql := &sqlbuilder.DQL{
+		With: &sqlbuilder.WithClause{Tables: []sqlbuilder.Table{
+			sqlbuilder.NameAsTable("a", sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "SELECT * FROM t WHERE x > ?", 1)),
+		}},
+		Select: sqlbuilder.Select{Columns: []string{"a.id", "count(*)"}},
+		From:   sqlbuilder.FromTableName("a"),
+		Joins: []sqlbuilder.Join{
+			{Kind: "JOIN", Table: sqlbuilder.TableByName("b"), On: []sqlbuilder.Condition{sqlbuilder.NewCondition("a.id = b.id AND b.y = ?", 2)}},
+			{Kind: "LEFT JOIN", Table: sqlbuilder.TableAsName(&sqlbuilder.DQL{From: sqlbuilder.FromTableName("c")}, "c")},
+		},
+		Where:  sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewCondition("a.z = ?", 3)}},
+		Group:  sqlbuilder.MakeGroupby("a.id"),
+		Having: sqlbuilder.HavingClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewCondition("count(*) > ?", 4)}},
+		Order:  sqlbuilder.MakeOrderby("a.id"),
+		Limit:  sqlbuilder.MakeLimit("?", 5),
+		Lock:   sqlbuilder.Lock{Strength: sqlbuilder.ForUpdate, Of: []string{"a"}, Wait: sqlbuilder.SkipLocked},
+		Additional: sqlbuilder.Clauses{
+			sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "UNION ALL"),
+			&sqlbuilder.DQL{From: sqlbuilder.FromTableName("d"), Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewCondition("w = ?", 6)}}},
+		},
+	}
+	for _, dialect := range []sqlbuilder.Dialect{sqlbuilder.Generic, sqlbuilder.PostgreSQL} {
+		for _, level := range []int{sqlbuilder.Format, sqlbuilder.Compact, 1} {
+			var buff, clausesBuff bytes.Buffer
+			args, clausesArgs := sqlbuilder.NewArgList(dialect, 0), sqlbuilder.NewArgList(dialect, 0)
+			Expect(dql.Parse(&buff, args, level)).Should(Succeed())
+			clauses := dql.Clauses()
+			Expect(clauses.Parse(&clausesBuff, clausesArgs, level)).Should(Succeed())
+			Expect(buff.String()).Should(Equal(clausesBuff.String()))
+			Expect(args.Args).Should(Equal(clausesArgs.Args))
+			Expect(args.Args).Should(Equal([]sqlbuilder.Arg{1, 2, 3, 4, 5, 6}))
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// The kinds of errors from Parse, check them by errors.Is.
+var (
+	// The clause tree is invalid, such as wrong number of arguments or unknown enum values.
+	ErrInvalidStructure = errors.New("invalid structure")
+	// The feature is not supported by the dialect.
+	ErrUnsupported = errors.New("unsupported")
+	// The sqlWriter or argWriter failed.
+	ErrWrite = errors.New("write failed")
+)
+
+// kindError keeps the message of err, and matches the kind by errors.Is.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// Create an error of ErrInvalidStructure.
+func NewInvalidError(format string, args ...any) error {
+	return &kindError{kind: ErrInvalidStructure, err: fmt.Errorf(format, args...)}
+}
+
+// Create an error of ErrUnsupported.
+func NewUnsupportedError(dialect Dialect, feature string) error {
+	return &kindError{kind: ErrUnsupported, err: fmt.Errorf("%s is not supported by %s", feature, dialect)}
+}
+
+// Create an error of ErrWrite, err is the error of the writer.
+func newWriteError(err error) error {
+	return &kindError{kind: ErrWrite, err: err}
+}
+
+/*
+ParseError locates the error in the clause tree, errors.Is and errors.As see through it.
+The Path is the fields from the root to the failing node, such as "DQL.From.Table.Clause.Where.Conditions[2]",
+the type of the root is added by Build and Renderer only.
+*/
+type ParseError struct {
+	Path string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+/*
+Locate the error in the field of the node, the segment is the field name such as "Where" or "Conditions[2]".
+It's used by the clauses containing other clauses or conditions when the children fail,
+so that the segments are prepended from the failing node to the root.
+*/
+func Locate(err error, segment string) error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(*ParseError); ok {
+		return &ParseError{Path: joinPath(segment, e.Path), Err: e.Err}
+	}
+	return &ParseError{Path: segment, Err: err}
+}
+
+// Locate the error in the index-th element of the field, such as "Conditions[2]".
+func LocateIndex(err error, field string, index int) error {
+	if err == nil {
+		return nil
+	}
+	return Locate(err, fmt.Sprintf("%s[%d]", field, index))
+}
+
+func joinPath(parent, child string) string {
+	if parent == "" || strings.HasPrefix(child, "[") {
+		return parent + child
+	}
+	if child == "" {
+		return parent
+	}
+	return parent + "." + child
+}
+
+// Locate the error in the root clause by its type name.
+func locateRoot(err error, root Clause) error {
+	if err == nil {
+		return nil
+	}
+	t := reflect.TypeOf(root)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return Locate(err, t.Name())
+}
@@ -0,0 +1,68 @@
+package sqlbuilder_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestParseError(t *testing.T) {
+	t.Run("located", func(t *testing.T) {
+		RegisterTestingT(t)
+		sub := &sqlbuilder.DQL{
+			From: sqlbuilder.FromTableName("flows"),
+			Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{
+				sqlbuilder.NewColumnCondition("a", "=", 1),
+				sqlbuilder.Or(sqlbuilder.NewColumnCondition("b", "=", 1), sqlbuilder.NewColumnCondition("c", "BETWEEN", 1), sqlbuilder.SaveBrackets),
+			}},
+		}
+		dql := &sqlbuilder.DQL{From: sqlbuilder.FromTable(sub)}
+		_, _, err := sqlbuilder.Build(dql, sqlbuilder.Generic, sqlbuilder.Format)
+		Expect(err).Should(MatchError("DQL.From.Table.Clause.Where.Conditions[1].R: c BETWEEN expects 2 arguments, but got 1"))
+		Expect(errors.Is(err, sqlbuilder.ErrInvalidStructure)).Should(BeTrue())
+		Expect(errors.Is(err, sqlbuilder.ErrWrite)).Should(BeFalse())
+		var parseErr *sqlbuilder.ParseError
+		Expect(errors.As(err, &parseErr)).Should(BeTrue())
+		Expect(parseErr.Path).Should(Equal("DQL.From.Table.Clause.Where.Conditions[1].R"))
+
+		cs := sqlbuilder.Clauses{sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "SELECT 1"), &sqlbuilder.DQL{
+			With: &sqlbuilder.WithClause{Tables: []sqlbuilder.Table{sqlbuilder.NameAsTable("t", &sqlbuilder.Update{
+				Table: "t",
+				Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewColumnCondition("a", "~", 1)}},
+			})}},
+		}}
+		_, _, err = sqlbuilder.Build(&cs, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(MatchError(`Clauses[1].With.Tables[0].Clause.Where.Conditions[0]: unknown operator "~" of column a`))
+	})
+	t.Run("bad name position", func(t *testing.T) {
+		RegisterTestingT(t)
+		dql := &sqlbuilder.DQL{From: sqlbuilder.From{Table: sqlbuilder.Table{Clause: &sqlbuilder.DQL{}, Name: "t", NamePosition: 2}}}
+		_, _, err := sqlbuilder.Build(dql, sqlbuilder.Generic, sqlbuilder.Format)
+		Expect(err).Should(MatchError("DQL.From: bad NamePosition 2"))
+		Expect(errors.Is(err, sqlbuilder.ErrInvalidStructure)).Should(BeTrue())
+
+		with := &sqlbuilder.WithClause{Tables: []sqlbuilder.Table{{Clause: &sqlbuilder.DQL{}, Name: "t", NamePosition: 2}}}
+		_, _, err = sqlbuilder.Build(with, sqlbuilder.Generic, sqlbuilder.Format)
+		Expect(err).Should(MatchError("WithClause.Tables[0]: bad NamePosition 2"))
+	})
+	t.Run("write failed", func(t *testing.T) {
+		RegisterTestingT(t)
+		dql := &sqlbuilder.DQL{
+			From:  sqlbuilder.FromTableName("flows"),
+			Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewColumnCondition("a", "=", 1)}},
+		}
+		err := dql.Parse(newFixedBuilder(30), sqlbuilder.NewArgList(sqlbuilder.Generic, 0), sqlbuilder.Format)
+		Expect(err).Should(MatchError("Where.Conditions[0]: write string failed, expect: 1, real: 0"))
+		Expect(errors.Is(err, sqlbuilder.ErrWrite)).Should(BeTrue())
+		Expect(errors.Is(err, sqlbuilder.ErrInvalidStructure)).Should(BeFalse())
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, _, err := sqlbuilder.Build(&sqlbuilder.DropTable{Name: "t", Cascade: true}, sqlbuilder.SQLite, sqlbuilder.Format)
+		Expect(err).Should(MatchError("DropTable: DROP TABLE ... CASCADE is not supported by sqlite"))
+		Expect(errors.Is(err, sqlbuilder.ErrUnsupported)).Should(BeTrue())
+	})
+}
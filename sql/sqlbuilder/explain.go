@@ -0,0 +1,240 @@
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+/*
+Explain wraps a statement to show its plan.
+PostgreSQL and Generic: EXPLAIN (ANALYZE, FORMAT JSON) ...
+MySQL: EXPLAIN ANALYZE ... or EXPLAIN FORMAT=JSON ..., they can't be used together.
+SQLite: EXPLAIN QUERY PLAN ..., neither Analyze nor JSON is supported.
+Note: The statement is executed if Analyze is true.
+*/
+type Explain struct {
+	Clause  Clause
+	Analyze bool
+	JSON    bool
+}
+
+func (c *Explain) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	if c.Clause == nil {
+		return Locate(NewInvalidError("the clause to explain is nil"), "Clause")
+	}
+	head := "EXPLAIN"
+	switch dialect := DialectOf(argWriter); dialect {
+	case SQLite:
+		if c.Analyze || c.JSON {
+			return NewUnsupportedError(dialect, "EXPLAIN ANALYZE or FORMAT JSON")
+		}
+		head += " QUERY PLAN"
+	case MySQL:
+		switch {
+		case c.Analyze && c.JSON:
+			return NewUnsupportedError(dialect, "EXPLAIN ANALYZE with FORMAT JSON")
+		case c.Analyze:
+			head += " ANALYZE"
+		case c.JSON:
+			head += " FORMAT=JSON"
+		}
+	default:
+		var options []string
+		if c.Analyze {
+			options = append(options, "ANALYZE")
+		}
+		if c.JSON {
+			options = append(options, "FORMAT JSON")
+		}
+		if len(options) != 0 {
+			head += " (" + strings.Join(options, ", ") + ")"
+		}
+	}
+	err := NewKeywordClause(AutoNewline, head, "").Parse(sqlWriter, argWriter, level)
+	if err != nil {
+		return err
+	}
+	return Locate(c.Clause.Parse(sqlWriter, argWriter, level), "Clause")
+}
+
+// PlanNode is a node of the query plan.
+type PlanNode struct {
+	// Such as "SEARCH flows USING INDEX idx_name (name=?)" in SQLite, or "Index Scan" in PostgreSQL.
+	Detail   string
+	Relation string
+	Index    string
+	Children []*PlanNode
+	// The properties of the node in the PostgreSQL JSON plan, such as "Total Cost".
+	Properties map[string]any
+}
+
+// Walk the plan in depth-first order, the children are skipped if fn returns false.
+func (n *PlanNode) Walk(fn func(node *PlanNode) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// Whether any node of the plan uses the index.
+func (n *PlanNode) UsesIndex(index string) bool {
+	found := false
+	n.Walk(func(node *PlanNode) bool {
+		found = found || node.Index == index
+		return !found
+	})
+	return found
+}
+
+// Whether any node of the plan scans the whole table without index.
+func (n *PlanNode) ScansTable(table string) bool {
+	found := false
+	n.Walk(func(node *PlanNode) bool {
+		found = found || node.Relation == table && node.Index == "" &&
+			(strings.HasPrefix(node.Detail, "SCAN ") || node.Detail == "Seq Scan")
+		return !found
+	})
+	return found
+}
+
+func (n *PlanNode) String() string {
+	var builder strings.Builder
+	n.write(&builder, 0)
+	return builder.String()
+}
+
+func (n *PlanNode) write(builder *strings.Builder, level int) {
+	if n.Detail != "" {
+		builder.WriteString(GetSpace(level))
+		builder.WriteString(n.Detail)
+		builder.WriteString(EOL)
+		level++
+	}
+	for _, child := range n.Children {
+		child.write(builder, level)
+	}
+}
+
+// The row of EXPLAIN QUERY PLAN in SQLite.
+type SQLitePlanRow struct {
+	ID     int
+	Parent int
+	Detail string
+}
+
+var (
+	sqlitePlanRelation = regexp.MustCompile(`^(?:SCAN|SEARCH)(?: TABLE)? (\S+)`)
+	sqlitePlanIndex    = regexp.MustCompile(`USING (?:COVERING )?INDEX (\S+)`)
+)
+
+// Build the plan from the rows of EXPLAIN QUERY PLAN in SQLite, the root node has no Detail.
+func ParseSQLitePlan(rows []SQLitePlanRow) *PlanNode {
+	root := &PlanNode{}
+	nodes := map[int]*PlanNode{0: root}
+	for _, row := range rows {
+		node := &PlanNode{Detail: row.Detail}
+		if match := sqlitePlanRelation.FindStringSubmatch(row.Detail); match != nil {
+			node.Relation = match[1]
+		}
+		if match := sqlitePlanIndex.FindStringSubmatch(row.Detail); match != nil {
+			node.Index = match[1]
+		}
+		parent, ok := nodes[row.Parent]
+		if !ok {
+			parent = root
+		}
+		parent.Children = append(parent.Children, node)
+		nodes[row.ID] = node
+	}
+	return root
+}
+
+// Build the plan from the output of EXPLAIN (FORMAT JSON) in PostgreSQL, the root node has no Detail.
+func ParsePostgreSQLPlan(data []byte) (*PlanNode, error) {
+	var plans []struct {
+		Plan map[string]any `json:"Plan"`
+	}
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return nil, err
+	}
+	root := &PlanNode{}
+	for _, p := range plans {
+		if p.Plan == nil {
+			return nil, errors.New("no plan in the output")
+		}
+		root.Children = append(root.Children, postgresqlPlanNode(p.Plan))
+	}
+	return root, nil
+}
+
+func postgresqlPlanNode(properties map[string]any) *PlanNode {
+	node := &PlanNode{Properties: properties}
+	node.Detail, _ = properties["Node Type"].(string)
+	node.Relation, _ = properties["Relation Name"].(string)
+	node.Index, _ = properties["Index Name"].(string)
+	children, _ := properties["Plans"].([]any)
+	for _, child := range children {
+		if p, ok := child.(map[string]any); ok {
+			node.Children = append(node.Children, postgresqlPlanNode(p))
+		}
+	}
+	delete(properties, "Plans")
+	return node
+}
+
+// Queryer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Explain the statement on the database, and parse the plan, PostgreSQL and SQLite are supported.
+func QueryPlan(ctx context.Context, db Queryer, dialect Dialect, c Clause) (*PlanNode, error) {
+	explain := &Explain{Clause: c, JSON: dialect == PostgreSQL}
+	if dialect != PostgreSQL && dialect != SQLite {
+		return nil, NewUnsupportedError(dialect, "parsing the plan")
+	}
+	query, args, err := Build(explain, dialect, Compact)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, toAny(args)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if dialect == PostgreSQL {
+		var data []byte
+		if !rows.Next() {
+			if err = rows.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("no plan returned")
+		}
+		if err = rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		return ParsePostgreSQLPlan(data)
+	}
+	var planRows []SQLitePlanRow
+	for rows.Next() {
+		var (
+			row     SQLitePlanRow
+			notused int
+		)
+		if err = rows.Scan(&row.ID, &row.Parent, &notused, &row.Detail); err != nil {
+			return nil, err
+		}
+		planRows = append(planRows, row)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return ParseSQLitePlan(planRows), nil
+}
@@ -0,0 +1,83 @@
+package sqlbuilder_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	_ "modernc.org/sqlite"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestExplain(t *testing.T) {
+	q := sqlbuilder.NewQuery("flows").Where(sqlbuilder.NewColumnCondition("name", "=", "a"))
+	t.Run("dialects", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args, err := sqlbuilder.Build(&sqlbuilder.Explain{Clause: q, Analyze: true, JSON: true}, sqlbuilder.PostgreSQL, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("EXPLAIN (ANALYZE, FORMAT JSON)\nSELECT *\nFROM flows\nWHERE\n  name = $1\n"))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"a"}))
+		sql, _, err = sqlbuilder.Build(&sqlbuilder.Explain{Clause: q, JSON: true}, sqlbuilder.MySQL, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("EXPLAIN FORMAT=JSON SELECT * FROM flows WHERE name = ? "))
+		sql, _, err = sqlbuilder.Build(&sqlbuilder.Explain{Clause: q}, sqlbuilder.SQLite, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("EXPLAIN QUERY PLAN SELECT * FROM flows WHERE name = ? "))
+		_, _, err = sqlbuilder.Build(&sqlbuilder.Explain{Clause: q, Analyze: true}, sqlbuilder.SQLite, sqlbuilder.Compact)
+		Expect(err).Should(MatchError("Explain: EXPLAIN ANALYZE or FORMAT JSON is not supported by sqlite"))
+	})
+	t.Run("nil clause", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, _, err := sqlbuilder.Build(&sqlbuilder.Explain{}, sqlbuilder.PostgreSQL, sqlbuilder.Compact)
+		Expect(err).Should(MatchError(sqlbuilder.ErrInvalidStructure))
+		Expect(err).Should(MatchError("Explain.Clause: the clause to explain is nil"))
+	})
+	t.Run("postgresql plan", func(t *testing.T) {
+		RegisterTestingT(t)
+		plan, err := sqlbuilder.ParsePostgreSQLPlan([]byte(`[{"Plan": {"Node Type": "Nested Loop", "Total Cost": 12.5, "Plans": [
+			{"Node Type": "Index Scan", "Relation Name": "flows", "Index Name": "flows_name_idx"},
+			{"Node Type": "Seq Scan", "Relation Name": "labels"}
+		]}, "Planning Time": 0.1}]`))
+		Expect(err).Should(Succeed())
+		Expect(plan.String()).Should(Equal("Nested Loop\n  Index Scan\n  Seq Scan\n"))
+		Expect(plan.UsesIndex("flows_name_idx")).Should(BeTrue())
+		Expect(plan.ScansTable("labels")).Should(BeTrue())
+		Expect(plan.ScansTable("flows")).Should(BeFalse())
+		Expect(plan.Children[0].Properties["Total Cost"]).Should(Equal(12.5))
+
+		_, err = sqlbuilder.ParsePostgreSQLPlan([]byte(`[{}]`))
+		Expect(err).Should(MatchError("no plan in the output"))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		RegisterTestingT(t)
+		ctx := context.Background()
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+		Expect(err).Should(Succeed())
+		defer db.Close()
+		_, err = db.Exec("CREATE TABLE flows (id INTEGER PRIMARY KEY, name TEXT, mtu INTEGER)")
+		Expect(err).Should(Succeed())
+		_, err = db.Exec("CREATE INDEX flows_name_idx ON flows (name)")
+		Expect(err).Should(Succeed())
+
+		plan, err := sqlbuilder.QueryPlan(ctx, db, sqlbuilder.SQLite, q)
+		Expect(err).Should(Succeed())
+		Expect(plan.UsesIndex("flows_name_idx")).Should(BeTrue(), plan.String())
+		Expect(plan.ScansTable("flows")).Should(BeFalse(), plan.String())
+
+		plan, err = sqlbuilder.QueryPlan(ctx, db, sqlbuilder.SQLite, q.Where(sqlbuilder.NewColumnCondition("mtu", ">", 1500)).
+			Where(sqlbuilder.Or(sqlbuilder.NewColumnCondition("name", "=", "a"), sqlbuilder.NewColumnCondition("mtu", "<", 1), sqlbuilder.SaveBrackets)))
+		Expect(err).Should(Succeed())
+		Expect(plan.UsesIndex("flows_name_idx")).Should(BeTrue(), plan.String())
+
+		plan, err = sqlbuilder.QueryPlan(ctx, db, sqlbuilder.SQLite, sqlbuilder.NewQuery("flows").Where(sqlbuilder.NewColumnCondition("mtu", "=", 1)))
+		Expect(err).Should(Succeed())
+		Expect(plan.UsesIndex("flows_name_idx")).Should(BeFalse(), plan.String())
+		Expect(plan.ScansTable("flows")).Should(BeTrue(), plan.String())
+
+		_, err = sqlbuilder.QueryPlan(ctx, db, sqlbuilder.MySQL, q)
+		Expect(err).Should(MatchError("parsing the plan is not supported by mysql"))
+	})
+}
@@ -0,0 +1,76 @@
+package expr
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+type When struct {
+	Condition sqlbuilder.Condition
+	Then      sqlbuilder.Expression
+}
+
+// CASE WHEN ... THEN ... ELSE ... END, the methods return a new Case without modifying the receiver.
+type Case struct {
+	Whens []When
+	// ELSE is omitted if it's nil.
+	Else sqlbuilder.Expression
+}
+
+func CaseWhen(condition sqlbuilder.Condition, then sqlbuilder.Expression) Case {
+	return Case{Whens: []When{{Condition: condition, Then: then}}}
+}
+
+func (e Case) When(condition sqlbuilder.Condition, then sqlbuilder.Expression) Case {
+	whens := make([]When, len(e.Whens), len(e.Whens)+1)
+	copy(whens, e.Whens)
+	e.Whens = append(whens, When{Condition: condition, Then: then})
+	return e
+}
+
+func (e Case) Otherwise(els sqlbuilder.Expression) Case {
+	e.Else = els
+	return e
+}
+
+func (e Case) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	if len(e.Whens) == 0 {
+		return sqlbuilder.NewInvalidError("CASE without WHEN")
+	}
+	var err error
+	err = sqlbuilder.WriteKeyword(sqlWriter, "CASE")
+	if err != nil {
+		return err
+	}
+	for i, w := range e.Whens {
+		err = sqlbuilder.WriteKeyword(sqlWriter, " WHEN ")
+		if err != nil {
+			return err
+		}
+		err = w.Condition.Parse(sqlWriter, argWriter)
+		if err != nil {
+			return sqlbuilder.Locate(err, fmt.Sprintf("Whens[%d].Condition", i))
+		}
+		err = sqlbuilder.WriteKeyword(sqlWriter, " THEN ")
+		if err != nil {
+			return err
+		}
+		err = w.Then.Parse(sqlWriter, argWriter)
+		if err != nil {
+			return sqlbuilder.Locate(err, fmt.Sprintf("Whens[%d].Then", i))
+		}
+	}
+	if e.Else != nil {
+		err = sqlbuilder.WriteKeyword(sqlWriter, " ELSE ")
+		if err != nil {
+			return err
+		}
+		err = e.Else.Parse(sqlWriter, argWriter)
+		if err != nil {
+			return sqlbuilder.Locate(err, "Else")
+		}
+	}
+	return sqlbuilder.WriteKeyword(sqlWriter, " END")
+}
@@ -0,0 +1,30 @@
+package expr_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/expr"
+)
+
+func TestCase(t *testing.T) {
+	t.Run("when", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := expr.CaseWhen(sqlbuilder.NewColumnCondition("a", ">", 1), expr.Val("big"))
+		more := c.When(sqlbuilder.NewColumnCondition("a", "IS NULL"), expr.Col("b")).Otherwise(expr.Val("small"))
+		sql, args := build(more, sqlbuilder.PostgreSQL)
+		Expect(sql).Should(Equal("CASE WHEN a > $1 THEN $2 WHEN a IS NULL THEN b ELSE $3 END"))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{1, "big", "small"}))
+
+		sql, _ = build(c, sqlbuilder.Generic)
+		Expect(sql).Should(Equal("CASE WHEN a > ? THEN ? END"))
+	})
+	t.Run("empty", func(t *testing.T) {
+		RegisterTestingT(t)
+		var builder strings.Builder
+		Expect(expr.Case{}.Parse(&builder, sqlbuilder.NewArgList(sqlbuilder.Generic, 0))).ShouldNot(Succeed())
+	})
+}
@@ -0,0 +1,169 @@
+/*
+Package expr implements SQL expressions for sqlbuilder, such as aggregate functions, COALESCE, CASE, CAST and date truncation.
+The expressions carry their own arguments, and render the dialect specific SQL according to the argWriter.
+They can be used in sqlbuilder.Select.Expressions, conditions, sqlbuilder.GroupBy and sqlbuilder.OrderBy.
+*/
+package expr
+
+import (
+	"io"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+// Raw is a SQL text without arguments, such as a column name, it's not escaped.
+type Raw string
+
+func Col(name string) Raw {
+	return Raw(name)
+}
+
+func (e Raw) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	return sqlbuilder.WriteString(sqlWriter, string(e))
+}
+
+// Value is a bound argument, it's rendered as a placeholder.
+type Value struct {
+	Arg sqlbuilder.Arg
+}
+
+func Val(arg sqlbuilder.Arg) Value {
+	return Value{Arg: arg}
+}
+
+func (e Value) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	if err := sqlbuilder.WriteString(sqlWriter, sqlbuilder.NextPlaceholder(argWriter)); err != nil {
+		return err
+	}
+	return sqlbuilder.WriteArgs(argWriter, e.Arg)
+}
+
+// Func is a function call, such as "COALESCE(a, b)".
+type Func struct {
+	Name string
+	Args []sqlbuilder.Expression
+}
+
+func Call(name string, args ...sqlbuilder.Expression) Func {
+	return Func{Name: name, Args: args}
+}
+
+func (e Func) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	if err := sqlbuilder.WriteString(sqlWriter, e.Name+"("); err != nil {
+		return err
+	}
+	if err := sqlbuilder.ParseExpressions(sqlWriter, argWriter, ", ", e.Args...); err != nil {
+		return sqlbuilder.Locate(err, "Args")
+	}
+	return sqlbuilder.WriteString(sqlWriter, ")")
+}
+
+// COUNT(*)
+func CountAll() Raw {
+	return Raw("COUNT(*)")
+}
+
+func Count(e sqlbuilder.Expression) Func {
+	return Call("COUNT", e)
+}
+
+// COUNT(DISTINCT e)
+func CountDistinct(e sqlbuilder.Expression) Func {
+	return Call("COUNT", Distinct{Expression: e})
+}
+
+func Sum(e sqlbuilder.Expression) Func {
+	return Call("SUM", e)
+}
+
+func Avg(e sqlbuilder.Expression) Func {
+	return Call("AVG", e)
+}
+
+func Min(e sqlbuilder.Expression) Func {
+	return Call("MIN", e)
+}
+
+func Max(e sqlbuilder.Expression) Func {
+	return Call("MAX", e)
+}
+
+func Coalesce(es ...sqlbuilder.Expression) Func {
+	return Call("COALESCE", es...)
+}
+
+// The argument of aggregate functions with DISTINCT.
+type Distinct struct {
+	Expression sqlbuilder.Expression
+}
+
+func (e Distinct) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	if err := sqlbuilder.WriteKeyword(sqlWriter, "DISTINCT "); err != nil {
+		return err
+	}
+	return sqlbuilder.Locate(e.Expression.Parse(sqlWriter, argWriter), "Expression")
+}
+
+// CAST(e AS type), the type is not escaped.
+type Cast struct {
+	Expression sqlbuilder.Expression
+	Type       string
+}
+
+func CastAs(e sqlbuilder.Expression, typ string) Cast {
+	return Cast{Expression: e, Type: typ}
+}
+
+func (e Cast) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	if err := sqlbuilder.WriteKeyword(sqlWriter, "CAST("); err != nil {
+		return err
+	}
+	if err := e.Expression.Parse(sqlWriter, argWriter); err != nil {
+		return sqlbuilder.Locate(err, "Expression")
+	}
+	if err := sqlbuilder.WriteKeyword(sqlWriter, " AS "); err != nil {
+		return err
+	}
+	return sqlbuilder.WriteString(sqlWriter, e.Type+")")
+}
+
+// The expression with an alias in the select list, such as "COUNT(*) AS total".
+type Alias struct {
+	Expression sqlbuilder.Expression
+	Name       string
+}
+
+func As(e sqlbuilder.Expression, name string) Alias {
+	return Alias{Expression: e, Name: name}
+}
+
+func (e Alias) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	if err := e.Expression.Parse(sqlWriter, argWriter); err != nil {
+		return sqlbuilder.Locate(err, "Expression")
+	}
+	if err := sqlbuilder.WriteKeyword(sqlWriter, " AS "); err != nil {
+		return err
+	}
+	return sqlbuilder.WriteString(sqlWriter, e.Name)
+}
+
+// Binary is a binary operation, it can be used as a condition, such as Cmp(CountAll(), ">", Val(1)).
+type Binary struct {
+	Left  sqlbuilder.Expression
+	Op    string
+	Right sqlbuilder.Expression
+}
+
+func Cmp(left sqlbuilder.Expression, op string, right sqlbuilder.Expression) Binary {
+	return Binary{Left: left, Op: op, Right: right}
+}
+
+func (e Binary) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	if err := e.Left.Parse(sqlWriter, argWriter); err != nil {
+		return sqlbuilder.Locate(err, "Left")
+	}
+	if err := sqlbuilder.WriteKeyword(sqlWriter, " "+e.Op+" "); err != nil {
+		return err
+	}
+	return sqlbuilder.Locate(e.Right.Parse(sqlWriter, argWriter), "Right")
+}
@@ -0,0 +1,78 @@
+package expr_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/expr"
+)
+
+func build(e sqlbuilder.Expression, dialect sqlbuilder.Dialect) (string, []sqlbuilder.Arg) {
+	var builder strings.Builder
+	args := sqlbuilder.NewArgList(dialect, 0)
+	Expect(e.Parse(&builder, args)).Should(Succeed())
+	return builder.String(), args.Args
+}
+
+func TestFunctions(t *testing.T) {
+	t.Run("aggregate", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args := build(expr.Call("f",
+			expr.CountAll(),
+			expr.Count(expr.Col("a")),
+			expr.CountDistinct(expr.Col("b")),
+			expr.Sum(expr.Col("c")),
+			expr.Avg(expr.Col("d")),
+			expr.Min(expr.Col("e")),
+			expr.Max(expr.Col("f")),
+		), sqlbuilder.Generic)
+		Expect(sql).Should(Equal("f(COUNT(*), COUNT(a), COUNT(DISTINCT b), SUM(c), AVG(d), MIN(e), MAX(f))"))
+		Expect(args).Should(BeEmpty())
+	})
+	t.Run("args", func(t *testing.T) {
+		RegisterTestingT(t)
+		e := expr.As(expr.Coalesce(expr.Col("a"), expr.CastAs(expr.Val("1"), "INTEGER"), expr.Val(2)), "x")
+		sql, args := build(e, sqlbuilder.PostgreSQL)
+		Expect(sql).Should(Equal("COALESCE(a, CAST($1 AS INTEGER), $2) AS x"))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"1", 2}))
+	})
+	t.Run("condition", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args := build(sqlbuilder.And(
+			expr.Cmp(expr.Sum(expr.Col("bytes")), ">", expr.Val(100)),
+			sqlbuilder.NewColumnCondition("a", "=", 1), sqlbuilder.OmitBrackets,
+		), sqlbuilder.PostgreSQL)
+		Expect(sql).Should(Equal("SUM(bytes) > $1 AND a = $2"))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{100, 1}))
+	})
+}
+
+func TestSelect(t *testing.T) {
+	RegisterTestingT(t)
+	day := expr.TruncTime(expr.Day, expr.Col("created_at"))
+	q := sqlbuilder.NewQuery("flows").
+		SelectArgs("$1 AS tag", "t").
+		SelectExpr(expr.As(day, "day"), expr.As(expr.CountDistinct(expr.Col("src")), "srcs")).
+		Where(expr.Cmp(expr.Col("bytes"), ">", expr.Val(0))).
+		GroupByExpr(day).
+		Having(expr.Cmp(expr.CountAll(), ">=", expr.Val(2))).
+		OrderByTerms(sqlbuilder.Desc(expr.Sum(expr.Col("bytes"))), sqlbuilder.Asc(day))
+	sql, args, err := sqlbuilder.Build(q, sqlbuilder.PostgreSQL, sqlbuilder.Format)
+	Expect(err).Should(Succeed())
+	Expect(sql).Should(Equal(`SELECT
+  $1 AS tag,
+  date_trunc('day', created_at) AS day,
+  COUNT(DISTINCT src) AS srcs
+FROM flows
+WHERE
+  bytes > $2
+GROUP BY date_trunc('day', created_at)
+HAVING
+  COUNT(*) >= $3
+ORDER BY SUM(bytes) DESC, date_trunc('day', created_at) ASC
+`))
+	Expect(args).Should(Equal([]sqlbuilder.Arg{"t", 0, 2}))
+}
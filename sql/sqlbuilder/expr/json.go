@@ -0,0 +1,176 @@
+package expr
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+/*
+JSON extracts the value at the path from the JSON column, the segments of path are
+string keys of objects or int indexes of arrays, they are passed as bound arguments.
+PostgreSQL: e -> $1 -> $2, or e -> $1 ->> $2 if AsText.
+MySQL: JSON_EXTRACT(e, ?), or JSON_UNQUOTE(JSON_EXTRACT(e, ?)) if AsText, the argument is a path such as '$.a[0]'.
+SQLite: json_extract(e, ?), scalars are always returned as SQL values.
+The Generic dialect is not supported.
+*/
+type JSON struct {
+	Expression sqlbuilder.Expression
+	Path       []any
+	// Extract the value as text instead of JSON.
+	AsText bool
+}
+
+// Extract the JSON value at the path, such as JSONExtract(attrs, "labels", 0).
+func JSONExtract(e sqlbuilder.Expression, path ...any) JSON {
+	return JSON{Expression: e, Path: path}
+}
+
+// Extract the text value at the path, such as JSONText(attrs, "name").
+func JSONText(e sqlbuilder.Expression, path ...any) JSON {
+	return JSON{Expression: e, Path: path, AsText: true}
+}
+
+func (e JSON) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	dialect := sqlbuilder.DialectOf(argWriter)
+	if dialect != sqlbuilder.PostgreSQL {
+		path, err := jsonPath(e.Path)
+		if err != nil {
+			return err
+		}
+		switch dialect {
+		case sqlbuilder.MySQL:
+			var f sqlbuilder.Expression = Call("JSON_EXTRACT", e.Expression, Val(path))
+			if e.AsText {
+				f = Call("JSON_UNQUOTE", f)
+			}
+			return f.Parse(sqlWriter, argWriter)
+		case sqlbuilder.SQLite:
+			return Call("json_extract", e.Expression, Val(path)).Parse(sqlWriter, argWriter)
+		default:
+			return jsonUnsupported(dialect)
+		}
+	}
+	if len(e.Path) == 0 {
+		return sqlbuilder.NewInvalidError("empty JSON path")
+	}
+	if err := e.Expression.Parse(sqlWriter, argWriter); err != nil {
+		return sqlbuilder.Locate(err, "Expression")
+	}
+	for i, segment := range e.Path {
+		op := " -> "
+		if e.AsText && i == len(e.Path)-1 {
+			op = " ->> "
+		}
+		if err := sqlbuilder.WriteString(sqlWriter, op); err != nil {
+			return err
+		}
+		switch segment.(type) {
+		case string:
+			if err := Val(segment).Parse(sqlWriter, argWriter); err != nil {
+				return err
+			}
+		case int:
+			// The type of placeholder is ambiguous between the text and int operators.
+			if err := CastAs(Val(segment), "int").Parse(sqlWriter, argWriter); err != nil {
+				return err
+			}
+		default:
+			return sqlbuilder.NewInvalidError("JSON path segment %v is %T, but string or int is expected", segment, segment)
+		}
+	}
+	return nil
+}
+
+// JSONContains is a condition whether the JSON column contains the value, the value is marshaled to JSON.
+// PostgreSQL: e @> $1, MySQL: JSON_CONTAINS(e, ?), SQLite and Generic are not supported.
+type JSONContains struct {
+	Expression sqlbuilder.Expression
+	Value      any
+}
+
+func Contains(e sqlbuilder.Expression, value any) JSONContains {
+	return JSONContains{Expression: e, Value: value}
+}
+
+func (e JSONContains) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	value, err := json.Marshal(e.Value)
+	if err != nil {
+		return err
+	}
+	switch dialect := sqlbuilder.DialectOf(argWriter); dialect {
+	case sqlbuilder.PostgreSQL:
+		return Cmp(e.Expression, "@>", Val(string(value))).Parse(sqlWriter, argWriter)
+	case sqlbuilder.MySQL:
+		return Call("JSON_CONTAINS", e.Expression, Val(string(value))).Parse(sqlWriter, argWriter)
+	default:
+		return jsonUnsupported(dialect)
+	}
+}
+
+// JSONHasKey is a condition whether the JSON object has the top-level key.
+// PostgreSQL: e ? $1, MySQL: JSON_CONTAINS_PATH(e, 'one', ?), SQLite: json_type(e, ?) IS NOT NULL.
+type JSONHasKey struct {
+	Expression sqlbuilder.Expression
+	Key        string
+}
+
+func HasKey(e sqlbuilder.Expression, key string) JSONHasKey {
+	return JSONHasKey{Expression: e, Key: key}
+}
+
+func (e JSONHasKey) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	switch dialect := sqlbuilder.DialectOf(argWriter); dialect {
+	case sqlbuilder.PostgreSQL:
+		return Cmp(e.Expression, "?", Val(e.Key)).Parse(sqlWriter, argWriter)
+	case sqlbuilder.MySQL:
+		path, _ := jsonPath([]any{e.Key})
+		return Call("JSON_CONTAINS_PATH", e.Expression, Raw("'one'"), Val(path)).Parse(sqlWriter, argWriter)
+	case sqlbuilder.SQLite:
+		path, _ := jsonPath([]any{e.Key})
+		return Cmp(Call("json_type", e.Expression, Val(path)), "IS NOT", Raw("NULL")).Parse(sqlWriter, argWriter)
+	default:
+		return jsonUnsupported(dialect)
+	}
+}
+
+// Convert the segments to the JSON path of MySQL and SQLite, such as `$.a."b c"[0]`.
+func jsonPath(segments []any) (string, error) {
+	var builder strings.Builder
+	builder.WriteString("$")
+	for _, segment := range segments {
+		switch s := segment.(type) {
+		case string:
+			builder.WriteString(".")
+			if isIdentifier(s) {
+				builder.WriteString(s)
+			} else {
+				builder.WriteString(strconv.Quote(s))
+			}
+		case int:
+			builder.WriteString("[" + strconv.Itoa(s) + "]")
+		default:
+			return "", sqlbuilder.NewInvalidError("JSON path segment %v is %T, but string or int is expected", segment, segment)
+		}
+	}
+	return builder.String(), nil
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && (i == 0 || !(r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonUnsupported(dialect sqlbuilder.Dialect) error {
+	return sqlbuilder.NewUnsupportedError(dialect, "JSON operator")
+}
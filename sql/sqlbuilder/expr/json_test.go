@@ -0,0 +1,72 @@
+package expr_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/expr"
+)
+
+func TestJSON(t *testing.T) {
+	attrs := expr.Col("attrs")
+	t.Run("postgresql", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args := build(sqlbuilder.And(
+			expr.Cmp(expr.JSONText(attrs, "labels", 0, "name"), "=", expr.Val("web")),
+			sqlbuilder.And(expr.Contains(attrs, map[string]int{"port": 80}), expr.HasKey(attrs, "vlan"), sqlbuilder.OmitBrackets),
+			sqlbuilder.OmitBrackets,
+		), sqlbuilder.PostgreSQL)
+		Expect(sql).Should(Equal("attrs -> $1 -> CAST($2 AS int) ->> $3 = $4 AND attrs @> $5 AND attrs ? $6"))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"labels", 0, "name", "web", `{"port":80}`, "vlan"}))
+	})
+	t.Run("mysql", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args := build(expr.Call("f",
+			expr.JSONExtract(attrs, "labels", 0),
+			expr.JSONText(attrs, "a b"),
+			expr.Contains(attrs, []string{"x"}),
+			expr.HasKey(attrs, "vlan"),
+		), sqlbuilder.MySQL)
+		Expect(sql).Should(Equal("f(JSON_EXTRACT(attrs, ?), JSON_UNQUOTE(JSON_EXTRACT(attrs, ?)), JSON_CONTAINS(attrs, ?), JSON_CONTAINS_PATH(attrs, 'one', ?))"))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"$.labels[0]", `$."a b"`, `["x"]`, "$.vlan"}))
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		RegisterTestingT(t)
+		var builder strings.Builder
+		Expect(expr.Contains(attrs, 1).Parse(&builder, sqlbuilder.NewArgList(sqlbuilder.SQLite, 0))).
+			Should(MatchError("JSON operator is not supported by sqlite"))
+		Expect(expr.JSONExtract(attrs, "a").Parse(&builder, sqlbuilder.NewArgList(sqlbuilder.Generic, 0))).
+			Should(MatchError("JSON operator is not supported by generic"))
+		Expect(expr.JSONExtract(attrs, 1.5).Parse(&builder, sqlbuilder.NewArgList(sqlbuilder.PostgreSQL, 0))).
+			Should(MatchError("JSON path segment 1.5 is float64, but string or int is expected"))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		RegisterTestingT(t)
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+		Expect(err).Should(Succeed())
+		defer db.Close()
+		_, err = db.Exec("CREATE TABLE events (id INTEGER, attrs TEXT)")
+		Expect(err).Should(Succeed())
+		_, err = db.Exec(`INSERT INTO events VALUES (1, '{"labels":[{"name":"web"}],"vlan":10}'), (2, '{"labels":[{"name":"db"}]}')`)
+		Expect(err).Should(Succeed())
+
+		q := sqlbuilder.NewQuery("events").
+			SelectExpr(expr.Col("id"), expr.JSONText(attrs, "labels", 0, "name")).
+			Where(expr.HasKey(attrs, "vlan"))
+		query, args, err := sqlbuilder.Build(q, sqlbuilder.SQLite, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(query).Should(Equal("SELECT id, json_extract(attrs, ?) FROM events WHERE json_type(attrs, ?) IS NOT NULL "))
+		var (
+			id   int
+			name string
+		)
+		Expect(db.QueryRow(query, args[0], args[1]).Scan(&id, &name)).Should(Succeed())
+		Expect(id).Should(Equal(1))
+		Expect(name).Should(Equal("web"))
+	})
+}
@@ -0,0 +1,127 @@
+package expr
+
+import (
+	"io"
+	"strings"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+/*
+Search is a full-text search condition, the query is passed as a bound argument.
+PostgreSQL: to_tsvector('config', a || ' ' || b) @@ plainto_tsquery('config', $1), the config is optional.
+MySQL: MATCH (a, b) AGAINST (? IN BOOLEAN MODE), a FULLTEXT index on the columns is required.
+SQLite: fts MATCH ?, where fts is the FTS5 table, the columns are ignored.
+The Generic dialect is not supported.
+*/
+type Search struct {
+	Columns []string
+	// The FTS5 table of SQLite.
+	Table string
+	// The text search configuration of PostgreSQL, such as "english".
+	Config string
+	Query  string
+}
+
+func FullText(query string, columns ...string) Search {
+	return Search{Columns: columns, Query: query}
+}
+
+// Set the FTS5 table for SQLite.
+func (e Search) InTable(table string) Search {
+	e.Table = table
+	return e
+}
+
+// Set the text search configuration for PostgreSQL.
+func (e Search) WithConfig(config string) Search {
+	e.Config = config
+	return e
+}
+
+func (e Search) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	switch dialect := sqlbuilder.DialectOf(argWriter); dialect {
+	case sqlbuilder.PostgreSQL:
+		if len(e.Columns) == 0 {
+			return sqlbuilder.NewInvalidError("no column to search")
+		}
+		return Cmp(e.vector(), "@@", e.tsquery()).Parse(sqlWriter, argWriter)
+	case sqlbuilder.MySQL:
+		return e.against(sqlWriter, argWriter)
+	case sqlbuilder.SQLite:
+		if e.Table == "" {
+			return sqlbuilder.NewInvalidError("the FTS5 table is required by %s", dialect)
+		}
+		return Cmp(Raw(e.Table), "MATCH", Val(e.Query)).Parse(sqlWriter, argWriter)
+	default:
+		return sqlbuilder.NewUnsupportedError(dialect, "full-text search")
+	}
+}
+
+// Get the relevance of rows, the more relevant rows have greater ranks, so it's usually sorted in descending order.
+// PostgreSQL: ts_rank(...), MySQL: MATCH (...) AGAINST (...), SQLite: -bm25(fts).
+func (e Search) Rank() sqlbuilder.Expression {
+	return rank(e)
+}
+
+type rank Search
+
+func (r rank) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	e := Search(r)
+	switch dialect := sqlbuilder.DialectOf(argWriter); dialect {
+	case sqlbuilder.PostgreSQL:
+		return Call("ts_rank", e.vector(), e.tsquery()).Parse(sqlWriter, argWriter)
+	case sqlbuilder.MySQL:
+		return e.against(sqlWriter, argWriter)
+	case sqlbuilder.SQLite:
+		return Raw("-bm25("+e.Table+")").Parse(sqlWriter, argWriter)
+	default:
+		return sqlbuilder.NewUnsupportedError(dialect, "full-text search")
+	}
+}
+
+func (e Search) vector() sqlbuilder.Expression {
+	columns := make([]string, len(e.Columns))
+	for i, c := range e.Columns {
+		columns[i] = "coalesce(" + c + ", '')"
+	}
+	document := Raw(strings.Join(columns, " || ' ' || "))
+	if len(e.Columns) == 1 {
+		document = Raw(e.Columns[0])
+	}
+	if e.Config == "" {
+		return Call("to_tsvector", document)
+	}
+	return Call("to_tsvector", quote(e.Config), document)
+}
+
+func (e Search) tsquery() sqlbuilder.Expression {
+	if e.Config == "" {
+		return Call("plainto_tsquery", Val(e.Query))
+	}
+	return Call("plainto_tsquery", quote(e.Config), Val(e.Query))
+}
+
+func (e Search) against(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	if len(e.Columns) == 0 {
+		return sqlbuilder.NewInvalidError("no column to search")
+	}
+	if err := sqlbuilder.WriteKeyword(sqlWriter, "MATCH ("); err != nil {
+		return err
+	}
+	if err := sqlbuilder.WriteString(sqlWriter, strings.Join(e.Columns, ", ")); err != nil {
+		return err
+	}
+	if err := sqlbuilder.WriteKeyword(sqlWriter, ") AGAINST ("); err != nil {
+		return err
+	}
+	if err := Val(e.Query).Parse(sqlWriter, argWriter); err != nil {
+		return err
+	}
+	return sqlbuilder.WriteKeyword(sqlWriter, " IN BOOLEAN MODE)")
+}
+
+// Quote the string as a SQL literal.
+func quote(s string) Raw {
+	return Raw("'" + strings.ReplaceAll(s, "'", "''") + "'")
+}
@@ -0,0 +1,74 @@
+package expr_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/expr"
+)
+
+func TestSearch(t *testing.T) {
+	t.Run("postgresql", func(t *testing.T) {
+		RegisterTestingT(t)
+		s := expr.FullText("web server", "title", "body").WithConfig("english")
+		q := sqlbuilder.NewQuery("docs").Select("id").Where(s).OrderByTerms(sqlbuilder.Desc(s.Rank()))
+		sql, args, err := sqlbuilder.Build(q, sqlbuilder.PostgreSQL, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT id FROM docs WHERE to_tsvector('english', coalesce(title, '') || ' ' || coalesce(body, '')) " +
+			"@@ plainto_tsquery('english', $1) ORDER BY ts_rank(to_tsvector('english', coalesce(title, '') || ' ' || coalesce(body, '')), " +
+			"plainto_tsquery('english', $2)) DESC "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"web server", "web server"}))
+
+		sql, _ = build(expr.FullText("x", "title"), sqlbuilder.PostgreSQL)
+		Expect(sql).Should(Equal("to_tsvector(title) @@ plainto_tsquery($1)"))
+	})
+	t.Run("mysql", func(t *testing.T) {
+		RegisterTestingT(t)
+		s := expr.FullText("+web -db", "title", "body")
+		sql, args := build(expr.Call("f", s, s.Rank()), sqlbuilder.MySQL)
+		Expect(sql).Should(Equal("f(MATCH (title, body) AGAINST (? IN BOOLEAN MODE), MATCH (title, body) AGAINST (? IN BOOLEAN MODE))"))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"+web -db", "+web -db"}))
+	})
+	t.Run("errors", func(t *testing.T) {
+		RegisterTestingT(t)
+		var builder strings.Builder
+		Expect(expr.FullText("x").Parse(&builder, sqlbuilder.NewArgList(sqlbuilder.SQLite, 0))).
+			Should(MatchError("the FTS5 table is required by sqlite"))
+		Expect(expr.FullText("x").Parse(&builder, sqlbuilder.NewArgList(sqlbuilder.MySQL, 0))).
+			Should(MatchError("no column to search"))
+		Expect(expr.FullText("x", "a").Parse(&builder, sqlbuilder.NewArgList(sqlbuilder.Generic, 0))).
+			Should(MatchError("full-text search is not supported by generic"))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		RegisterTestingT(t)
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+		Expect(err).Should(Succeed())
+		defer db.Close()
+		_, err = db.Exec("CREATE VIRTUAL TABLE docs_fts USING fts5(title, body)")
+		Expect(err).Should(Succeed())
+		_, err = db.Exec(`INSERT INTO docs_fts VALUES ('web', 'nothing'), ('web server', 'web server web'), ('database', 'postgres')`)
+		Expect(err).Should(Succeed())
+
+		s := expr.FullText("web").InTable("docs_fts")
+		q := sqlbuilder.NewQuery("docs_fts").Select("title").Where(s).OrderByTerms(sqlbuilder.Desc(s.Rank()))
+		query, args, err := sqlbuilder.Build(q, sqlbuilder.SQLite, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(query).Should(Equal("SELECT title FROM docs_fts WHERE docs_fts MATCH ? ORDER BY -bm25(docs_fts) DESC "))
+		rows, err := db.Query(query, args[0])
+		Expect(err).Should(Succeed())
+		defer rows.Close()
+		var titles []string
+		for rows.Next() {
+			var title string
+			Expect(rows.Scan(&title)).Should(Succeed())
+			titles = append(titles, title)
+		}
+		Expect(rows.Err()).Should(Succeed())
+		Expect(titles).Should(Equal([]string{"web server", "web"}))
+	})
+}
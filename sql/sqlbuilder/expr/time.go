@@ -0,0 +1,70 @@
+package expr
+
+import (
+	"io"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+// The unit of DateTrunc.
+type TimeUnit string
+
+const (
+	Year   TimeUnit = "year"
+	Month  TimeUnit = "month"
+	Day    TimeUnit = "day"
+	Hour   TimeUnit = "hour"
+	Minute TimeUnit = "minute"
+	Second TimeUnit = "second"
+)
+
+// The formats used to truncate the time in MySQL and SQLite.
+var (
+	mysqlTimeFormats = map[TimeUnit]string{
+		Year:   "%Y-01-01 00:00:00",
+		Month:  "%Y-%m-01 00:00:00",
+		Day:    "%Y-%m-%d 00:00:00",
+		Hour:   "%Y-%m-%d %H:00:00",
+		Minute: "%Y-%m-%d %H:%i:00",
+		Second: "%Y-%m-%d %H:%i:%s",
+	}
+	sqliteTimeFormats = map[TimeUnit]string{
+		Year:   "%Y-01-01 00:00:00",
+		Month:  "%Y-%m-01 00:00:00",
+		Day:    "%Y-%m-%d 00:00:00",
+		Hour:   "%Y-%m-%d %H:00:00",
+		Minute: "%Y-%m-%d %H:%M:00",
+		Second: "%Y-%m-%d %H:%M:%S",
+	}
+)
+
+/*
+DateTrunc truncates the time to the unit, the unit is rendered as a literal so that
+the same expression in SELECT and GROUP BY are identical.
+PostgreSQL and Generic: date_trunc('day', e)
+MySQL: DATE_FORMAT(e, '%Y-%m-%d 00:00:00')
+SQLite: strftime('%Y-%m-%d 00:00:00', e)
+*/
+type DateTrunc struct {
+	Unit       TimeUnit
+	Expression sqlbuilder.Expression
+}
+
+func TruncTime(unit TimeUnit, e sqlbuilder.Expression) DateTrunc {
+	return DateTrunc{Unit: unit, Expression: e}
+}
+
+func (e DateTrunc) Parse(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+	format, ok := mysqlTimeFormats[e.Unit]
+	if !ok {
+		return sqlbuilder.NewInvalidError("unknown time unit %q", e.Unit)
+	}
+	switch sqlbuilder.DialectOf(argWriter) {
+	case sqlbuilder.MySQL:
+		return Call("DATE_FORMAT", e.Expression, Raw("'"+format+"'")).Parse(sqlWriter, argWriter)
+	case sqlbuilder.SQLite:
+		return Call("strftime", Raw("'"+sqliteTimeFormats[e.Unit]+"'"), e.Expression).Parse(sqlWriter, argWriter)
+	default:
+		return Call("date_trunc", Raw("'"+string(e.Unit)+"'"), e.Expression).Parse(sqlWriter, argWriter)
+	}
+}
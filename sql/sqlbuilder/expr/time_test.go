@@ -0,0 +1,67 @@
+package expr_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	_ "modernc.org/sqlite"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/expr"
+)
+
+func TestDateTrunc(t *testing.T) {
+	t.Run("dialects", func(t *testing.T) {
+		RegisterTestingT(t)
+		e := expr.TruncTime(expr.Hour, expr.Col("ts"))
+		sql, _ := build(e, sqlbuilder.PostgreSQL)
+		Expect(sql).Should(Equal("date_trunc('hour', ts)"))
+		sql, _ = build(e, sqlbuilder.MySQL)
+		Expect(sql).Should(Equal("DATE_FORMAT(ts, '%Y-%m-%d %H:00:00')"))
+		sql, _ = build(e, sqlbuilder.SQLite)
+		Expect(sql).Should(Equal("strftime('%Y-%m-%d %H:00:00', ts)"))
+	})
+	t.Run("unknown unit", func(t *testing.T) {
+		RegisterTestingT(t)
+		var builder strings.Builder
+		err := expr.TruncTime("week", expr.Col("ts")).Parse(&builder, sqlbuilder.NewArgList(sqlbuilder.SQLite, 0))
+		Expect(err).Should(MatchError(`unknown time unit "week"`))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		RegisterTestingT(t)
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+		Expect(err).Should(Succeed())
+		defer db.Close()
+		_, err = db.Exec("CREATE TABLE events (ts TEXT, n INTEGER)")
+		Expect(err).Should(Succeed())
+		_, err = db.Exec("INSERT INTO events VALUES ('2024-05-01 10:20:30', 1), ('2024-05-01 23:00:00', 2), ('2024-05-02 01:00:00', 4)")
+		Expect(err).Should(Succeed())
+
+		day := expr.TruncTime(expr.Day, expr.Col("ts"))
+		q := sqlbuilder.NewQuery("events").
+			SelectExpr(day, expr.Sum(expr.Col("n"))).
+			GroupByExpr(day).
+			OrderByTerms(sqlbuilder.Asc(day))
+		query, args, err := sqlbuilder.Build(q, sqlbuilder.SQLite, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(args).Should(BeEmpty())
+		rows, err := db.Query(query)
+		Expect(err).Should(Succeed())
+		defer rows.Close()
+		var res []string
+		for rows.Next() {
+			var (
+				d   string
+				sum int
+			)
+			Expect(rows.Scan(&d, &sum)).Should(Succeed())
+			res = append(res, d+"="+strconv.Itoa(sum))
+		}
+		Expect(rows.Err()).Should(Succeed())
+		Expect(res).Should(Equal([]string{"2024-05-01 00:00:00=3", "2024-05-02 00:00:00=4"}))
+	})
+}
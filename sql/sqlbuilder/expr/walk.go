@@ -0,0 +1,107 @@
+package expr
+
+import (
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+// The expressions containing other expressions implement sqlbuilder.ParentExpression, so that sqlbuilder.Apply
+// can traverse the children, such as the sub queries and the columns validated by sqlbuilder.Catalog.
+
+func (e Func) Children() []sqlbuilder.Expression {
+	return append([]sqlbuilder.Expression(nil), e.Args...)
+}
+
+func (e Func) WithChildren(children []sqlbuilder.Expression) sqlbuilder.Expression {
+	e.Args = children
+	return e
+}
+
+func (e Distinct) Children() []sqlbuilder.Expression {
+	return []sqlbuilder.Expression{e.Expression}
+}
+
+func (e Distinct) WithChildren(children []sqlbuilder.Expression) sqlbuilder.Expression {
+	e.Expression = children[0]
+	return e
+}
+
+func (e Cast) Children() []sqlbuilder.Expression {
+	return []sqlbuilder.Expression{e.Expression}
+}
+
+func (e Cast) WithChildren(children []sqlbuilder.Expression) sqlbuilder.Expression {
+	e.Expression = children[0]
+	return e
+}
+
+func (e Alias) Children() []sqlbuilder.Expression {
+	return []sqlbuilder.Expression{e.Expression}
+}
+
+func (e Alias) WithChildren(children []sqlbuilder.Expression) sqlbuilder.Expression {
+	e.Expression = children[0]
+	return e
+}
+
+func (e Binary) Children() []sqlbuilder.Expression {
+	return []sqlbuilder.Expression{e.Left, e.Right}
+}
+
+func (e Binary) WithChildren(children []sqlbuilder.Expression) sqlbuilder.Expression {
+	e.Left, e.Right = children[0], children[1]
+	return e
+}
+
+// The conditions and results of WHEN in order, followed by ELSE.
+func (e Case) Children() []sqlbuilder.Expression {
+	children := make([]sqlbuilder.Expression, 0, 2*len(e.Whens)+1)
+	for _, w := range e.Whens {
+		children = append(children, w.Condition, w.Then)
+	}
+	return append(children, e.Else)
+}
+
+func (e Case) WithChildren(children []sqlbuilder.Expression) sqlbuilder.Expression {
+	whens := make([]When, len(e.Whens))
+	for i := range whens {
+		whens[i] = When{Condition: children[2*i], Then: children[2*i+1]}
+	}
+	e.Whens, e.Else = whens, children[len(children)-1]
+	return e
+}
+
+func (e JSON) Children() []sqlbuilder.Expression {
+	return []sqlbuilder.Expression{e.Expression}
+}
+
+func (e JSON) WithChildren(children []sqlbuilder.Expression) sqlbuilder.Expression {
+	e.Expression = children[0]
+	return e
+}
+
+func (e JSONContains) Children() []sqlbuilder.Expression {
+	return []sqlbuilder.Expression{e.Expression}
+}
+
+func (e JSONContains) WithChildren(children []sqlbuilder.Expression) sqlbuilder.Expression {
+	e.Expression = children[0]
+	return e
+}
+
+func (e JSONHasKey) Children() []sqlbuilder.Expression {
+	return []sqlbuilder.Expression{e.Expression}
+}
+
+func (e JSONHasKey) WithChildren(children []sqlbuilder.Expression) sqlbuilder.Expression {
+	e.Expression = children[0]
+	return e
+}
+
+func (e DateTrunc) Children() []sqlbuilder.Expression {
+	return []sqlbuilder.Expression{e.Expression}
+}
+
+func (e DateTrunc) WithChildren(children []sqlbuilder.Expression) sqlbuilder.Expression {
+	e.Expression = children[0]
+	return e
+}
@@ -0,0 +1,147 @@
+package sqlbuilder
+
+import (
+	"io"
+)
+
+/*
+Expression is a SQL expression carrying its own arguments, such as a column, a function call or a CASE expression.
+It has the same method as Condition, so a Condition can be used as a boolean expression and vice versa.
+Expressions can be used in Select.Expressions, conditions, GroupBy and OrderBy, see package expr for more expressions.
+*/
+type Expression interface {
+	Parse(sqlWriter io.StringWriter, argWriter ArgWriter) error
+}
+
+// Parse the expressions separated by the sep, such as ", ", the errors are located by the indexes.
+func ParseExpressions(sqlWriter io.StringWriter, argWriter ArgWriter, sep string, exprs ...Expression) error {
+	for i, e := range exprs {
+		if i != 0 {
+			if err := WriteString(sqlWriter, sep); err != nil {
+				return err
+			}
+		}
+		if err := e.Parse(sqlWriter, argWriter); err != nil {
+			return LocateIndex(err, "", i)
+		}
+	}
+	return nil
+}
+
+// The clause such as "GROUP BY a, b", the head is followed by the expressions.
+type expressionsClause struct {
+	head  string
+	exprs []Expression
+}
+
+func (c *expressionsClause) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	var err error
+	err = WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, c.head+" ")
+	if err != nil {
+		return err
+	}
+	err = ParseExpressions(sqlWriter, argWriter, ", ", c.exprs...)
+	if err != nil {
+		return Locate(err, "Expressions")
+	}
+	return EndLine(sqlWriter, CompactLevel(level))
+}
+
+// Make GROUP BY clause from expressions, such as GroupBy(expr.TruncTime(expr.Day, createdAt)). It's nil if there is no expression.
+func GroupBy(exprs ...Expression) Group {
+	if len(exprs) == 0 {
+		return nil
+	}
+	return &expressionsClause{head: "GROUP BY", exprs: append([]Expression(nil), exprs...)}
+}
+
+// A term of ORDER BY, the Column is used only if the Expression is nil.
+type OrderTerm struct {
+	Expression Expression
+	Column     string
+	Desc       bool
+}
+
+func Asc(e Expression) OrderTerm {
+	return OrderTerm{Expression: e}
+}
+
+func Desc(e Expression) OrderTerm {
+	return OrderTerm{Expression: e, Desc: true}
+}
+
+func (t OrderTerm) Parse(sqlWriter io.StringWriter, argWriter ArgWriter) error {
+	if t.Expression == nil {
+		if t.Column == "" {
+			return NewInvalidError("empty term of ORDER BY")
+		}
+		if err := WriteString(sqlWriter, t.Column); err != nil {
+			return err
+		}
+	} else if err := t.Expression.Parse(sqlWriter, argWriter); err != nil {
+		return Locate(err, "Expression")
+	}
+	if t.Desc {
+		return WriteKeyword(sqlWriter, " DESC")
+	}
+	return WriteKeyword(sqlWriter, " ASC")
+}
+
+// Make ORDER BY clause from terms, such as OrderBy(id.Desc(), name.Asc()). It's nil if there is no term.
+func OrderBy(terms ...OrderTerm) Order {
+	if len(terms) == 0 {
+		return nil
+	}
+	exprs := make([]Expression, len(terms))
+	for i, t := range terms {
+		exprs[i] = t
+	}
+	return &expressionsClause{head: "ORDER BY", exprs: exprs}
+}
+
+// SubQuery is a sub query used as an expression, such as the list of IN or a scalar in the select list.
+// The Clause is parsed in Compact mode and enclosed in brackets, Apply traverses it like the other sub queries.
+type SubQuery struct {
+	Clause Clause
+}
+
+func NewSubQuery(clause Clause) SubQuery {
+	return SubQuery{Clause: clause}
+}
+
+func (e SubQuery) Parse(sqlWriter io.StringWriter, argWriter ArgWriter) error {
+	if e.Clause == nil {
+		return Locate(NewInvalidError("sub query is nil"), "Clause")
+	}
+	var err error
+	err = WriteString(sqlWriter, "(")
+	if err != nil {
+		return err
+	}
+	err = EndLine(sqlWriter, true)
+	if err != nil {
+		return err
+	}
+	err = e.Clause.Parse(sqlWriter, argWriter, Compact)
+	if err != nil {
+		return Locate(err, "Clause")
+	}
+	return WriteString(sqlWriter, ")")
+}
+
+/*
+ParentExpression is implemented by the expressions containing other expressions, such as the ones in package expr,
+so that Apply can traverse the children, such as a SubQuery in a comparison.
+The other expressions and conditions not implemented in this package are leaves.
+*/
+type ParentExpression interface {
+	Expression
+	// Get the children in order, the slice is a new one.
+	Children() []Expression
+	// Get a copy of the expression with the children replaced, the children are in the order of Children.
+	WithChildren(children []Expression) Expression
+}
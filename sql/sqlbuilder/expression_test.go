@@ -0,0 +1,54 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestExpression(t *testing.T) {
+	t.Run("select", func(t *testing.T) {
+		RegisterTestingT(t)
+		s := &sqlbuilder.Select{
+			Columns:     []string{"a"},
+			Args:        []sqlbuilder.Arg{1},
+			Expressions: []sqlbuilder.Expression{flowID, sqlbuilder.NewColumnCondition("b", "=", 2)},
+		}
+		sql, args, err := sqlbuilder.Build(s, sqlbuilder.PostgreSQL, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT\n  a,\n  flows.id,\n  b = $2\n"))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{1, 2}))
+
+		c := s.Clone()
+		c.Expressions[0] = flowName
+		Expect(s.Expressions[0]).Should(Equal(flowID))
+
+		sql, _, err = sqlbuilder.Build(&sqlbuilder.Select{Expressions: []sqlbuilder.Expression{flowID}}, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT flows.id "))
+	})
+	t.Run("group and order", func(t *testing.T) {
+		RegisterTestingT(t)
+		dql := &sqlbuilder.DQL{
+			Select: sqlbuilder.Select{Expressions: []sqlbuilder.Expression{flowName}},
+			From:   sqlbuilder.FromTableName("flows"),
+			Group:  sqlbuilder.GroupBy(flowName, sqlbuilder.SimpleCondition{Str: "substr(x, ?)", Args: []sqlbuilder.Arg{2}}),
+			Order:  sqlbuilder.OrderBy(sqlbuilder.Desc(sqlbuilder.SimpleCondition{Str: "count(*)"}), flowName.Asc()),
+		}
+		sql, args, err := sqlbuilder.Build(dql, sqlbuilder.PostgreSQL, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT\n  flows.name\nFROM flows\nGROUP BY flows.name, substr(x, ?)\nORDER BY count(*) DESC, flows.name ASC\n"))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{2}))
+	})
+	t.Run("order by column", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, _, err := sqlbuilder.Build(sqlbuilder.OrderBy(sqlbuilder.OrderTerm{Column: "id", Desc: true}, flowName.Asc()), sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("ORDER BY id DESC, flows.name ASC "))
+		_, _, err = sqlbuilder.Build(sqlbuilder.OrderBy(sqlbuilder.OrderTerm{}), sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(MatchError(sqlbuilder.ErrInvalidStructure))
+		Expect(sqlbuilder.GroupBy()).Should(BeNil())
+	})
+}
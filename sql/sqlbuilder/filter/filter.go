@@ -0,0 +1,331 @@
+/*
+Package filter parses the filter expressions of APIs into sqlbuilder conditions, such as:
+
+	status in (up, down) and (name ~ "eth*" or mtu > 1500)
+
+The grammar is:
+
+	expr       = term { "or" term }
+	term       = factor { "and" factor }
+	factor     = "not" factor | "(" expr ")" | comparison
+	comparison = field op value | field ["not"] "in" "(" value { "," value } ")"
+	op         = "=" | "!=" | "<>" | ">" | ">=" | "<" | "<=" | "~" | "!~"
+	value      = word | quoted string
+
+The keywords are case-insensitive. The ~ and !~ match the value as a glob by LIKE, * matches any characters and ? matches one character,
+other characters such as % and _ match themselves.
+Comparing with the bare word null by = or != produces IS NULL or IS NOT NULL, quote it as "null" to compare with the string.
+Only the fields in the allow-list can be used, the values are converted by the types of fields and always bound as arguments.
+*/
+package filter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+// The maximum depth of nested parentheses and not, it protects the parser from malicious filters.
+const maxDepth = 32
+
+// Field is a field which can be used in filters.
+type Field struct {
+	// The column of the field, such as "interfaces.mtu".
+	Column string
+	// The values are converted to the type, AnyType keeps quoted values as strings
+	// and converts bare words to int64, float64 or bool if possible.
+	Type sqlbuilder.ColumnType
+}
+
+// Error is a user-facing error of the filter, it's safe to return it to the clients of API.
+type Error struct {
+	// The byte offset in the filter where the error occurs.
+	Offset int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Offset+1)
+}
+
+func newError(offset int, format string, args ...any) *Error {
+	return &Error{Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Parser parses filters with the allow-list of fields, it's safe for concurrent use.
+type Parser struct {
+	fields map[string]Field
+}
+
+// Create a parser with the allow-list mapping the field names of API to the fields.
+func NewParser(fields map[string]Field) *Parser {
+	return &Parser{fields: fields}
+}
+
+// Parse the filter into a condition, it's nil if the filter is blank, the error is an *Error.
+func (p *Parser) Parse(filter string) (sqlbuilder.Condition, error) {
+	tokens, err := lex(filter)
+	if err != nil {
+		return nil, err
+	}
+	if tokens[0].kind == tokenEOF {
+		return nil, nil
+	}
+	s := &state{parser: p, tokens: tokens}
+	c, err := s.expr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := s.peek(); t.kind != tokenEOF {
+		return nil, newError(t.offset, "unexpected %q", t.text)
+	}
+	return c, nil
+}
+
+type state struct {
+	parser *Parser
+	tokens []token
+	pos    int
+}
+
+func (s *state) peek() token {
+	return s.tokens[s.pos]
+}
+
+func (s *state) next() token {
+	t := s.tokens[s.pos]
+	if t.kind != tokenEOF {
+		s.pos++
+	}
+	return t
+}
+
+// Whether the next token is the keyword, it's consumed if so.
+func (s *state) keyword(keyword string) bool {
+	if t := s.peek(); t.kind == tokenWord && strings.EqualFold(t.text, keyword) {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+func (s *state) expect(kind tokenKind, text string) (token, error) {
+	t := s.next()
+	if t.kind != kind {
+		return t, unexpected(t, text)
+	}
+	return t, nil
+}
+
+func unexpected(t token, expected string) *Error {
+	if t.kind == tokenEOF {
+		return newError(t.offset, "expected %s, but the filter ends", expected)
+	}
+	return newError(t.offset, "expected %s, but got %q", expected, t.text)
+}
+
+func (s *state) expr(depth int) (sqlbuilder.Condition, error) {
+	l, err := s.term(depth)
+	if err != nil {
+		return nil, err
+	}
+	for s.keyword("or") {
+		r, err := s.term(depth)
+		if err != nil {
+			return nil, err
+		}
+		l = sqlbuilder.Or(l, r, sqlbuilder.SaveBrackets)
+	}
+	return l, nil
+}
+
+func (s *state) term(depth int) (sqlbuilder.Condition, error) {
+	l, err := s.factor(depth)
+	if err != nil {
+		return nil, err
+	}
+	for s.keyword("and") {
+		r, err := s.factor(depth)
+		if err != nil {
+			return nil, err
+		}
+		l = sqlbuilder.And(l, r, sqlbuilder.OmitBrackets)
+	}
+	return l, nil
+}
+
+func (s *state) factor(depth int) (sqlbuilder.Condition, error) {
+	t := s.peek()
+	if depth > maxDepth {
+		return nil, newError(t.offset, "the filter is nested too deeply")
+	}
+	if s.keyword("not") {
+		c, err := s.factor(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		// AND and OR are bracketed to keep the precedence.
+		_, compound := c.(sqlbuilder.AndCondition)
+		return sqlbuilder.Not(sqlbuilder.BracketIf(c, compound), sqlbuilder.OmitBrackets), nil
+	}
+	if t.kind == tokenLParen {
+		s.pos++
+		c, err := s.expr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = s.expect(tokenRParen, `")"`); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return s.comparison()
+}
+
+func (s *state) comparison() (sqlbuilder.Condition, error) {
+	t := s.next()
+	if t.kind != tokenWord {
+		return nil, unexpected(t, "a field")
+	}
+	field, ok := s.parser.fields[t.text]
+	if !ok {
+		return nil, newError(t.offset, "unknown field %q", t.text)
+	}
+	switch {
+	case s.keyword("in"):
+		return s.in(t.text, field, "IN")
+	case s.keyword("not"):
+		if !s.keyword("in") {
+			return nil, unexpected(s.peek(), `"in"`)
+		}
+		return s.in(t.text, field, "NOT IN")
+	}
+	op, err := s.expect(tokenOp, "an operator")
+	if err != nil {
+		return nil, err
+	}
+	v := s.next()
+	if v.kind != tokenWord && v.kind != tokenString {
+		return nil, unexpected(v, "a value")
+	}
+	if v.kind == tokenWord && strings.EqualFold(v.text, "null") {
+		switch op.text {
+		case "=":
+			return sqlbuilder.NewColumnCondition(field.Column, "IS NULL"), nil
+		case "!=", "<>":
+			return sqlbuilder.NewColumnCondition(field.Column, "IS NOT NULL"), nil
+		default:
+			return nil, newError(op.offset, "%s can not be compared with null", op.text)
+		}
+	}
+	if op.text == "~" || op.text == "!~" {
+		if field.Type != sqlbuilder.StringType && field.Type != sqlbuilder.AnyType {
+			return nil, newError(op.offset, "%s can not be used on %s field %q", op.text, field.Type, t.text)
+		}
+		like := "LIKE"
+		if op.text == "!~" {
+			like = "NOT LIKE"
+		}
+		c := sqlbuilder.NewColumnCondition(field.Column, like, glob(v.text))
+		c.Escape = likeEscape
+		return c, nil
+	}
+	arg, err := convert(t.text, field, v)
+	if err != nil {
+		return nil, err
+	}
+	return sqlbuilder.NewColumnCondition(field.Column, op.text, arg), nil
+}
+
+func (s *state) in(name string, field Field, op string) (sqlbuilder.Condition, error) {
+	if _, err := s.expect(tokenLParen, `"("`); err != nil {
+		return nil, err
+	}
+	var args []sqlbuilder.Arg
+	for {
+		v := s.next()
+		if v.kind != tokenWord && v.kind != tokenString {
+			return nil, unexpected(v, "a value")
+		}
+		arg, err := convert(name, field, v)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		t := s.next()
+		if t.kind == tokenRParen {
+			return sqlbuilder.NewColumnCondition(field.Column, op, args...), nil
+		}
+		if t.kind != tokenComma {
+			return nil, unexpected(t, `"," or ")"`)
+		}
+	}
+}
+
+// The escape character of the patterns converted from globs.
+const likeEscape = `\`
+
+// Convert the glob to the pattern of LIKE escaped by likeEscape, the %, _ and \ in the glob match themselves.
+func glob(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "*", "%", "?", "_").Replace(s)
+}
+
+// Convert the value by the type of the field.
+func convert(name string, field Field, v token) (sqlbuilder.Arg, error) {
+	var (
+		arg sqlbuilder.Arg
+		err error
+	)
+	switch field.Type {
+	case sqlbuilder.AnyType:
+		return convertAny(v), nil
+	case sqlbuilder.IntType:
+		arg, err = strconv.ParseInt(v.text, 10, 64)
+	case sqlbuilder.FloatType:
+		arg, err = parseFloat(v.text)
+	case sqlbuilder.BoolType:
+		arg, err = strconv.ParseBool(v.text)
+	case sqlbuilder.TimeType:
+		arg, err = time.Parse(time.RFC3339, v.text)
+	case sqlbuilder.StringType:
+		return v.text, nil
+	default:
+		return nil, newError(v.offset, "field %q can not be filtered", name)
+	}
+	if err != nil {
+		return nil, newError(v.offset, "field %q expects %s, but got %q", name, field.Type, v.text)
+	}
+	return arg, nil
+}
+
+func convertAny(v token) sqlbuilder.Arg {
+	if v.kind == tokenString {
+		return v.text
+	}
+	if i, err := strconv.ParseInt(v.text, 10, 64); err == nil {
+		return i
+	}
+	if f, err := parseFloat(v.text); err == nil {
+		return f
+	}
+	switch strings.ToLower(v.text) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return v.text
+}
+
+// Parse the finite float, the words such as "inf" and "nan" are not numbers in filters.
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err == nil && (math.IsInf(f, 0) || math.IsNaN(f)) {
+		return 0, strconv.ErrSyntax
+	}
+	return f, err
+}
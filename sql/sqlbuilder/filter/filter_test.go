@@ -0,0 +1,99 @@
+package filter_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/filter"
+)
+
+var parser = filter.NewParser(map[string]filter.Field{
+	"status":  {Column: "interfaces.status", Type: sqlbuilder.StringType},
+	"name":    {Column: "interfaces.name", Type: sqlbuilder.StringType},
+	"mtu":     {Column: "interfaces.mtu", Type: sqlbuilder.IntType},
+	"speed":   {Column: "interfaces.speed", Type: sqlbuilder.FloatType},
+	"up":      {Column: "interfaces.up", Type: sqlbuilder.BoolType},
+	"created": {Column: "interfaces.created_at", Type: sqlbuilder.TimeType},
+	"label":   {Column: "interfaces.label"},
+})
+
+func build(t *testing.T, f string) (string, []sqlbuilder.Arg) {
+	c, err := parser.Parse(f)
+	Expect(err).ShouldNot(HaveOccurred())
+	sql, args, err := sqlbuilder.Build(&sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{c}}, sqlbuilder.PostgreSQL, sqlbuilder.Compact)
+	Expect(err).ShouldNot(HaveOccurred())
+	return sql, args
+}
+
+func TestParse(t *testing.T) {
+	t.Run("example", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args := build(t, `status in (up,down) and (name ~ "eth*" or mtu > 1500)`)
+		Expect(sql).Should(Equal(`WHERE interfaces.status IN ($1, $2) AND (interfaces.name LIKE $3 ESCAPE '\' OR interfaces.mtu > $4) `))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"up", "down", "eth%", int64(1500)}))
+	})
+	t.Run("precedence", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, _ := build(t, `mtu = 1 or mtu = 2 AND NOT (mtu = 3 and mtu = 4) or not (mtu = 5 or mtu = 6)`)
+		Expect(sql).Should(Equal("WHERE ((interfaces.mtu = $1 OR interfaces.mtu = $2 AND NOT (interfaces.mtu = $3 AND interfaces.mtu = $4)) " +
+			"OR NOT (interfaces.mtu = $5 OR interfaces.mtu = $6)) "))
+	})
+	t.Run("types", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args := build(t, `speed >= 2.5 and up = true and created < 2024-01-02T03:04:05Z and status not in ("a b", 'c\'d') and name !~ e?h`)
+		Expect(sql).Should(Equal("WHERE interfaces.speed >= $1 AND interfaces.up = $2 AND interfaces.created_at < $3 " +
+			`AND interfaces.status NOT IN ($4, $5) AND interfaces.name NOT LIKE $6 ESCAPE '\' `))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{2.5, true, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "a b", "c'd", "e_h"}))
+
+		_, args = build(t, `label in (1, 1.5, true, x, "2", inf)`)
+		Expect(args).Should(Equal([]sqlbuilder.Arg{int64(1), 1.5, true, "x", "2", "inf"}))
+	})
+	t.Run("literal wildcards", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args := build(t, `name ~ "eth_0*" or name !~ '100%' or name ~ 'a\\b'`)
+		Expect(sql).Should(Equal(`WHERE ((interfaces.name LIKE $1 ESCAPE '\' OR interfaces.name NOT LIKE $2 ESCAPE '\') ` +
+			`OR interfaces.name LIKE $3 ESCAPE '\') `))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{`eth\_0%`, `100\%`, `a\\b`}))
+	})
+	t.Run("null", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args := build(t, `name = null or name != NULL or name = "null"`)
+		Expect(sql).Should(Equal("WHERE ((interfaces.name IS NULL OR interfaces.name IS NOT NULL) OR interfaces.name = $1) "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"null"}))
+	})
+	t.Run("blank", func(t *testing.T) {
+		RegisterTestingT(t)
+		c, err := parser.Parse("  ")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(c).Should(BeNil())
+	})
+	t.Run("errors", func(t *testing.T) {
+		RegisterTestingT(t)
+		for f, msg := range map[string]string{
+			`password = 1`:         `unknown field "password" at position 1`,
+			`mtu > abc`:            `field "mtu" expects int, but got "abc" at position 7`,
+			`mtu ~ 1`:              `~ can not be used on int field "mtu" at position 5`,
+			`name = "eth`:          `unterminated string at position 8`,
+			`(name = a`:            `expected ")", but the filter ends at position 10`,
+			`name = a)`:            `unexpected ")" at position 9`,
+			`name a`:               `expected an operator, but got "a" at position 6`,
+			`status in (a b)`:      `expected "," or ")", but got "b" at position 14`,
+			`status not a`:         `expected "in", but got "a" at position 12`,
+			`mtu > null`:           `> can not be compared with null at position 5`,
+			`name ! a`:             `unexpected '!' at position 6`,
+			`name = a and`:         `expected a field, but the filter ends at position 13`,
+			`speed = nan`:          `field "speed" expects float, but got "nan" at position 9`,
+			`created > 2024-01-02`: `field "created" expects time, but got "2024-01-02" at position 11`,
+			`(((((((((((((((((((((((((((((((((name = a)))))))))))))))))))))))))))))))))`: `the filter is nested too deeply at position 34`,
+		} {
+			_, err := parser.Parse(f)
+			Expect(err).Should(MatchError(msg), f)
+			var e *filter.Error
+			Expect(errors.As(err, &e)).Should(BeTrue())
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package filter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF    tokenKind = iota
+	tokenWord             // Field names, keywords and bare values, such as status, and, up, 1500 or eth*.
+	tokenString           // Quoted values, such as "eth*" or 'a b'.
+	tokenOp               // Comparison operators, such as =, != and ~.
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	// The unquoted value of strings, or the text of other tokens.
+	text string
+	// The byte offset of the token in the filter.
+	offset int
+}
+
+var operators = []string{"!=", "<>", ">=", "<=", "!~", "=", ">", "<", "~"}
+
+// The characters which end a bare word.
+const delimiters = `()",'=!<>~`
+
+// Split the filter into tokens, the last token is always tokenEOF.
+func lex(filter string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(filter); {
+		r, size := utf8.DecodeRuneInString(filter[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", offset: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", offset: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", offset: i})
+			i++
+		case r == '"' || r == '\'':
+			str, end, err := lexString(filter, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: str, offset: i})
+			i = end
+		case strings.ContainsRune("=!<>~", r):
+			op := lexOperator(filter[i:])
+			if op == "" {
+				return nil, newError(i, "unexpected %q", r)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, offset: i})
+			i += len(op)
+		default:
+			end := i
+			for end < len(filter) {
+				r, size := utf8.DecodeRuneInString(filter[end:])
+				if unicode.IsSpace(r) || strings.ContainsRune(delimiters, r) {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: filter[i:end], offset: i})
+			i = end
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, offset: len(filter)}), nil
+}
+
+func lexOperator(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// Lex the string quoted by the character at begin, the backslash escapes the next character.
+func lexString(filter string, begin int) (string, int, error) {
+	quote := filter[begin]
+	var b strings.Builder
+	for i := begin + 1; i < len(filter); i++ {
+		switch c := filter[i]; c {
+		case quote:
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 == len(filter) {
+				return "", 0, newError(begin, "unterminated string")
+			}
+			i++
+			b.WriteByte(filter[i])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, newError(begin, "unterminated string")
+}
@@ -0,0 +1,188 @@
+package sqlbuilder
+
+import (
+	"bytes"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var inListPattern = regexp.MustCompile(`\bIN ?\(\?(?:, \?)*\)`)
+
+// The keywords are folded to upper case when normalizing, so that "in" and "IN" share a fingerprint.
+var fingerprintKeywords = func() map[string]bool {
+	keywords := make(map[string]bool)
+	for _, keyword := range strings.Fields(`
+		SELECT DISTINCT FROM WHERE AND OR NOT IN IS NULL LIKE ILIKE ESCAPE BETWEEN EXISTS ANY SOME ALL
+		AS ON USING JOIN LEFT RIGHT INNER OUTER FULL CROSS NATURAL LATERAL
+		GROUP BY HAVING ORDER ASC DESC NULLS FIRST LAST LIMIT OFFSET
+		UNION INTERSECT EXCEPT WITH RECURSIVE
+		INSERT INTO VALUES UPDATE SET DELETE RETURNING CONFLICT DO NOTHING DUPLICATE KEY
+		CASE WHEN THEN ELSE END CAST TRUE FALSE
+		FOR SHARE NOWAIT SKIP LOCKED OF OVER PARTITION FILTER EXPLAIN ANALYZE`) {
+		keywords[keyword] = true
+	}
+	return keywords
+}()
+
+const operatorChars = "<>=!+-*/%|&^~:"
+
+/*
+Fingerprint returns a stable hash and the normalized text of the clause, it's designed to label the shape of queries.
+The clause is parsed in Compact mode for the dialect, then it is normalized:
+1. Comments are removed, whitespaces are collapsed, and operators and commas are separated by a single space.
+2. Keywords outside quoted strings and identifiers are folded to upper case, other words are kept.
+3. String and numeric literals with their unary minus and placeholders such as $1 are replaced with "?".
+4. Lists such as IN (?, ?, ?) are collapsed to IN (?).
+So that queries which differ only in arguments, literals or the length of IN lists share a fingerprint.
+*/
+func Fingerprint(c Clause, dialect Dialect) (hash string, text string, err error) {
+	var buff bytes.Buffer
+	if err = c.Parse(&buff, NewArgList(dialect, 0), Compact); err != nil {
+		return "", "", err
+	}
+	text = inListPattern.ReplaceAllString(normalizeSQL(buff.String()), "IN (?)")
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(text))
+	return strconv.FormatUint(h.Sum64(), 16), text, nil
+}
+
+func normalizeSQL(str string) string {
+	var builder strings.Builder
+	builder.Grow(len(str))
+	// Spaces are written lazily, so that they can be dropped around brackets and commas.
+	space := false
+	// Whether the last token is an operand, a minus is unary if it's not.
+	operand := false
+	write := func(s string, isOperand bool) {
+		if space && builder.Len() > 0 && s != ")" && s != "," && !strings.HasSuffix(builder.String(), "(") {
+			builder.WriteByte(' ')
+		}
+		space = false
+		operand = isOperand
+		builder.WriteString(s)
+	}
+	for i := 0; i < len(str); {
+		ch := str[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			space = true
+			i++
+		case ch == '-' && strings.HasPrefix(str[i:], "--"):
+			end := strings.IndexByte(str[i:], '\n')
+			if end < 0 {
+				end = len(str) - i
+			}
+			space = true
+			i += end
+		case ch == '/' && strings.HasPrefix(str[i:], "/*"):
+			end := strings.Index(str[i+2:], "*/")
+			if end < 0 {
+				end = len(str) - i
+			} else {
+				end += 4
+			}
+			space = true
+			i += end
+		case ch == '\'':
+			write("?", true)
+			i = skipQuoted(str, i)
+		case ch == '"' || ch == '`':
+			end := skipQuoted(str, i)
+			write(str[i:end], true)
+			i = end
+		case ch == '$' && i+1 < len(str) && isDigit(str[i+1]):
+			i++
+			for i < len(str) && isDigit(str[i]) {
+				i++
+			}
+			write("?", true)
+		case isNumberStart(str, i):
+			i = skipNumber(str, i)
+			write("?", true)
+		case ch == '-' && !operand && isNumberStart(str, skipSpaces(str, i+1)):
+			// The unary minus is a part of the literal, so that -2 and 2 are both "?".
+			i = skipNumber(str, skipSpaces(str, i+1))
+			write("?", true)
+		case isNamePart(ch) || ch >= utf8.RuneSelf:
+			end := i + 1
+			for end < len(str) && (isNamePart(str[end]) || str[end] == '$' || str[end] >= utf8.RuneSelf) {
+				end++
+			}
+			word := str[i:end]
+			upper := strings.ToUpper(word)
+			keyword := fingerprintKeywords[upper]
+			if keyword {
+				word = upper
+			}
+			write(word, !keyword || upper == "NULL" || upper == "TRUE" || upper == "FALSE" || upper == "END")
+			i = end
+		case strings.IndexByte(operatorChars, ch) >= 0:
+			end := i + 1
+			for end < len(str) && strings.IndexByte(operatorChars, str[end]) >= 0 &&
+				!strings.HasPrefix(str[end:], "--") && !strings.HasPrefix(str[end:], "/*") &&
+				// The minus of a negative literal is not a part of the operator, such as "x>-2".
+				!(str[end] == '-' && isNumberStart(str, skipSpaces(str, end+1))) {
+				end++
+			}
+			space = true
+			write(str[i:end], false)
+			space = true
+			i = end
+		case ch == ',':
+			write(",", false)
+			space = true
+			i++
+		default:
+			write(str[i:i+1], ch == ')')
+			i++
+		}
+	}
+	return builder.String()
+}
+
+// Whether a numeric literal begins at str[i], such as 1 and .5.
+func isNumberStart(str string, i int) bool {
+	return i < len(str) && (isDigit(str[i]) || (str[i] == '.' && i+1 < len(str) && isDigit(str[i+1])))
+}
+
+func skipSpaces(str string, i int) int {
+	for i < len(str) && (str[i] == ' ' || str[i] == '\t' || str[i] == '\n' || str[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// Returns the index after the number begins at str[begin], such as 1, 1.5, .5, 1e10, 0x1F.
+func skipNumber(str string, begin int) int {
+	i := begin
+	if strings.HasPrefix(str[i:], "0x") || strings.HasPrefix(str[i:], "0X") {
+		i += 2
+		for i < len(str) && strings.IndexByte("0123456789abcdefABCDEF", str[i]) >= 0 {
+			i++
+		}
+		return i
+	}
+	for i < len(str) && (isDigit(str[i]) || str[i] == '.') {
+		i++
+	}
+	if i < len(str) && (str[i] == 'e' || str[i] == 'E') {
+		j := i + 1
+		if j < len(str) && (str[j] == '+' || str[j] == '-') {
+			j++
+		}
+		if j < len(str) && isDigit(str[j]) {
+			i = j
+			for i < len(str) && isDigit(str[i]) {
+				i++
+			}
+		}
+	}
+	return i
+}
@@ -0,0 +1,113 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/expr"
+)
+
+func TestFingerprint(t *testing.T) {
+	newDQL := func(conditions ...sqlbuilder.Condition) *sqlbuilder.DQL {
+		return &sqlbuilder.DQL{
+			Select: sqlbuilder.Select{Columns: []string{"id", "count(*) AS c"}},
+			From:   sqlbuilder.FromTableName("flows"),
+			Where:  sqlbuilder.WhereClause{Conditions: conditions},
+			Limit:  sqlbuilder.MakeLimit("10"),
+		}
+	}
+	t.Run("normalize", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, text, err := sqlbuilder.Fingerprint(newDQL(
+			sqlbuilder.NewCondition("name='eth0'"),
+			sqlbuilder.NewCondition("mtu>=1500.5 /* comment */"),
+			sqlbuilder.NewCondition(`"Id" IN ($1,$2,$3)`),
+		), sqlbuilder.Generic)
+		Expect(err).Should(Succeed())
+		Expect(text).Should(Equal(`SELECT id, count(*) AS c FROM flows WHERE name = ? AND mtu >= ? AND "Id" IN (?) LIMIT ?`))
+	})
+	t.Run("same shape", func(t *testing.T) {
+		RegisterTestingT(t)
+		hash1, text1, err := sqlbuilder.Fingerprint(newDQL(
+			sqlbuilder.NewCondition("name = ?", "eth0"),
+			sqlbuilder.NewCondition("id IN (?, ?, ?)", 1, 2, 3),
+		), sqlbuilder.Generic)
+		Expect(err).Should(Succeed())
+		hash2, text2, err := sqlbuilder.Fingerprint(newDQL(
+			sqlbuilder.NewCondition("name =  'eth1'"),
+			sqlbuilder.NewCondition("id in (?)", 1),
+		), sqlbuilder.Generic)
+		Expect(err).Should(Succeed())
+		Expect(text1).Should(Equal("SELECT id, count(*) AS c FROM flows WHERE name = ? AND id IN (?) LIMIT ?"))
+		Expect(text2).Should(Equal(text1))
+		Expect(hash1).ShouldNot(BeEmpty())
+		hash3, _, err := sqlbuilder.Fingerprint(newDQL(
+			sqlbuilder.NewCondition("name = ?", "eth2"),
+			sqlbuilder.NewCondition("id IN (4, 5)"),
+		), sqlbuilder.Generic)
+		Expect(err).Should(Succeed())
+		Expect(hash3).Should(Equal(hash1))
+		Expect(hash2).Should(Equal(hash1))
+	})
+	t.Run("different shape", func(t *testing.T) {
+		RegisterTestingT(t)
+		hash1, _, err := sqlbuilder.Fingerprint(newDQL(sqlbuilder.NewCondition("name = ?", "eth0")), sqlbuilder.Generic)
+		Expect(err).Should(Succeed())
+		hash2, _, err := sqlbuilder.Fingerprint(newDQL(sqlbuilder.NewCondition("mtu = ?", 1500)), sqlbuilder.Generic)
+		Expect(err).Should(Succeed())
+		Expect(hash1).ShouldNot(Equal(hash2))
+	})
+	t.Run("keep identifiers", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, text, err := sqlbuilder.Fingerprint(sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "SELECT col1, t2.x, 0x1F, 1e-3, `a1` FROM t2 WHERE y::int > -2"), sqlbuilder.Generic)
+		Expect(err).Should(Succeed())
+		Expect(text).Should(Equal("SELECT col1, t2.x, ?, ?, `a1` FROM t2 WHERE y :: int > ?"))
+	})
+	t.Run("fold keywords", func(t *testing.T) {
+		RegisterTestingT(t)
+		hash1, text1, err := sqlbuilder.Fingerprint(sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, `select Name from t where "in" in (1, 2) and x like 'In'`), sqlbuilder.Generic)
+		Expect(err).Should(Succeed())
+		hash2, text2, err := sqlbuilder.Fingerprint(sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, `SELECT Name FROM t WHERE "in" IN (3) AND x LIKE 'a'`), sqlbuilder.Generic)
+		Expect(err).Should(Succeed())
+		Expect(text1).Should(Equal(`SELECT Name FROM t WHERE "in" IN (?) AND x LIKE ?`))
+		Expect(text2).Should(Equal(text1))
+		Expect(hash2).Should(Equal(hash1))
+	})
+	t.Run("unary minus", func(t *testing.T) {
+		RegisterTestingT(t)
+		for c, expected := range map[string]string{
+			"x = -2":                 "x = ?",
+			"x=- 2":                  "x = ?",
+			"x = 2":                  "x = ?",
+			"x >-2 AND y IN (-1, 2)": "x > ? AND y IN (?)",
+			"(x) - 2":                "(x) - ?",
+			"x - 2":                  "x - ?",
+			"f(-.5) - -2":            "f(?) - ?",
+		} {
+			_, text, err := sqlbuilder.Fingerprint(sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, c), sqlbuilder.Generic)
+			Expect(err).Should(Succeed())
+			Expect(text).Should(Equal(expected), c)
+		}
+	})
+	t.Run("non-ASCII identifiers", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, text, err := sqlbuilder.Fingerprint(sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "SELECT nom_é, 名前 FROM t"), sqlbuilder.Generic)
+		Expect(err).Should(Succeed())
+		Expect(text).Should(Equal("SELECT nom_é, 名前 FROM t"))
+	})
+	t.Run("dialect", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := &sqlbuilder.DQL{
+			Select: sqlbuilder.Select{Expressions: []sqlbuilder.Expression{expr.JSONText(expr.Col("labels"), "env")}},
+			From:   sqlbuilder.FromTableName("t"),
+			Where:  sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewColumnCondition("id", "=", 1)}},
+		}
+		_, text, err := sqlbuilder.Fingerprint(c, sqlbuilder.PostgreSQL)
+		Expect(err).Should(Succeed())
+		Expect(text).Should(Equal("SELECT labels ->> ? FROM t WHERE id = ?"))
+		_, _, err = sqlbuilder.Fingerprint(c, sqlbuilder.Generic)
+		Expect(err).Should(MatchError(sqlbuilder.ErrUnsupported))
+	})
+}
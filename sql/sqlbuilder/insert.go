@@ -0,0 +1,118 @@
+package sqlbuilder
+
+import (
+	"io"
+	"strings"
+)
+
+/*
+The template of INSERT statement in Data Manipulation Language
+The rows are inserted from Values, or from Query such as a DQL if it's not nil.
+Additional clauses are placed at the end, such as "ON CONFLICT DO NOTHING" or "RETURNING id".
+*/
+type Insert struct {
+	With    With
+	Table   string
+	Columns []string
+	// Each row has the same length as Columns.
+	Values     [][]Arg
+	Query      Clause
+	Additional Clauses
+}
+
+func (l *Insert) Clauses() Clauses {
+	cs := make([]Clause, 0, 4+len(l.Additional))
+	if l.With != nil {
+		cs = append(cs, l.With)
+	}
+	head := l.Table
+	if len(l.Columns) != 0 {
+		head += " (" + strings.Join(l.Columns, ", ") + ")"
+	}
+	cs = append(cs, NewKeywordClause(AutoNewline, "INSERT INTO", head))
+	if l.Query != nil {
+		cs = append(cs, l.Query)
+	} else {
+		cs = append(cs, NewCustomClause(l.parseValues))
+	}
+	return appendClauses(cs, l.Additional)
+}
+
+func (l *Insert) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	var err error
+	if l.With != nil {
+		if err = l.With.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "With")
+		}
+	}
+	head := l.Table
+	if len(l.Columns) != 0 {
+		head += " (" + strings.Join(l.Columns, ", ") + ")"
+	}
+	if err = NewKeywordClause(AutoNewline, "INSERT INTO", head).Parse(sqlWriter, argWriter, level); err != nil {
+		return err
+	}
+	if l.Query != nil {
+		if err = l.Query.Parse(sqlWriter, argWriter, level); err != nil {
+			return Locate(err, "Query")
+		}
+	} else if err = l.parseValues(sqlWriter, argWriter, level); err != nil {
+		return err
+	}
+	return Locate(l.Additional.Parse(sqlWriter, argWriter, level), "Additional")
+}
+
+func (l *Insert) parseValues(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	if len(l.Values) == 0 {
+		return Locate(NewInvalidError("no rows to insert into %s", l.Table), "Values")
+	}
+	var err error
+	err = WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, "VALUES")
+	if err != nil {
+		return err
+	}
+	err = EndLine(sqlWriter, CompactLevel(level))
+	if err != nil {
+		return err
+	}
+	list := newListWriter(sqlWriter, NextLevel(level), len(l.Values))
+	for i, row := range l.Values {
+		if len(l.Columns) != 0 && len(row) != len(l.Columns) {
+			return LocateIndex(NewInvalidError("row has %d values, but %d columns are expected", len(row), len(l.Columns)), "Values", i)
+		}
+		err = list.item("(" + strings.Join(NextPlaceholders(argWriter, len(row)), ", ") + ")")
+		if err != nil {
+			return err
+		}
+		err = WriteArgs(argWriter, row...)
+		if err != nil {
+			return err
+		}
+	}
+	return list.finish()
+}
+
+func (l *Insert) Clone() *Insert {
+	if l == nil {
+		return nil
+	}
+	var values [][]Arg
+	if l.Values != nil {
+		values = make([][]Arg, len(l.Values))
+		for i, row := range l.Values {
+			values[i] = cloneArgs(row)
+		}
+	}
+	return &Insert{
+		With:       cloneWith(l.With),
+		Table:      l.Table,
+		Columns:    cloneStrings(l.Columns),
+		Values:     values,
+		Query:      CloneClause(l.Query),
+		Additional: l.Additional.Clone(),
+	}
+}
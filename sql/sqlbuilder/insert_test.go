@@ -0,0 +1,52 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestInsert(t *testing.T) {
+	t.Run("values", func(t *testing.T) {
+		RegisterTestingT(t)
+		insert := &sqlbuilder.Insert{
+			Table:      "flows",
+			Columns:    []string{"id", "name"},
+			Values:     [][]sqlbuilder.Arg{{1, "a"}, {2, "b"}},
+			Additional: sqlbuilder.Clauses{sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "ON CONFLICT DO NOTHING")},
+		}
+		sql, args, err := sqlbuilder.Build(insert, sqlbuilder.PostgreSQL, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("INSERT INTO flows (id, name)\nVALUES\n  ($1, $2),\n  ($3, $4)\nON CONFLICT DO NOTHING\n"))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{1, "a", 2, "b"}))
+
+		c := insert.Clone()
+		c.Values[0][0] = 3
+		Expect(insert.Values[0][0]).Should(Equal(1))
+		Expect(sqlbuilder.ReferencedTables(insert)).Should(Equal([]string{"flows"}))
+	})
+	t.Run("query", func(t *testing.T) {
+		RegisterTestingT(t)
+		insert := &sqlbuilder.Insert{
+			Table: "archived",
+			Query: &sqlbuilder.DQL{
+				From:  sqlbuilder.FromTableName("flows"),
+				Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewColumnCondition("id", "<", 10)}},
+			},
+		}
+		sql, args, err := sqlbuilder.Build(insert, sqlbuilder.MySQL, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("INSERT INTO archived SELECT * FROM flows WHERE id < ? "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{10}))
+		Expect(sqlbuilder.ReferencedTables(insert)).Should(Equal([]string{"archived", "flows"}))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, _, err := sqlbuilder.Build(&sqlbuilder.Insert{Table: "t", Columns: []string{"a"}}, sqlbuilder.MySQL, sqlbuilder.Compact)
+		Expect(err).Should(MatchError("Insert.Values: no rows to insert into t"))
+		_, _, err = sqlbuilder.Build(&sqlbuilder.Insert{Table: "t", Columns: []string{"a"}, Values: [][]sqlbuilder.Arg{{1, 2}}}, sqlbuilder.MySQL, sqlbuilder.Compact)
+		Expect(err).Should(MatchError("Insert.Values[0]: row has 2 values, but 1 columns are expected"))
+	})
+}
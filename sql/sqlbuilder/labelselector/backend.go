@@ -0,0 +1,162 @@
+package labelselector
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/expr"
+)
+
+/*
+LabelTable stores the labels in a table with a row per label, such as:
+
+	CREATE TABLE labels (object_id INTEGER, key TEXT, value TEXT, PRIMARY KEY (object_id, key))
+
+Each requirement is an EXISTS or NOT EXISTS sub query correlated to the owner column of the outer query.
+The values are compared as integers by GreaterThan and LessThan, the values which are not integers never match.
+*/
+type LabelTable struct {
+	Table string
+	// The column of the objects in the outer query, such as "interfaces.id".
+	Owner string
+	// The columns of the label table, they are qualified by the table.
+	ObjectColumn string
+	KeyColumn    string
+	ValueColumn  string
+}
+
+func (t LabelTable) Condition(r Requirement) sqlbuilder.Condition {
+	if err := r.validate(); err != nil {
+		return invalid(err)
+	}
+	value := t.Table + "." + t.ValueColumn
+	switch r.Operator {
+	case Exists:
+		return t.exists(r.Key, nil)
+	case DoesNotExist:
+		return sqlbuilder.Not(t.exists(r.Key, nil), sqlbuilder.OmitBrackets)
+	case Equals:
+		return t.exists(r.Key, sqlbuilder.NewColumnCondition(value, "=", toArgs(r.Values)...))
+	case NotEquals:
+		return sqlbuilder.Not(t.exists(r.Key, sqlbuilder.NewColumnCondition(value, "=", toArgs(r.Values)...)), sqlbuilder.OmitBrackets)
+	case In:
+		return t.exists(r.Key, sqlbuilder.NewColumnCondition(value, "IN", toArgs(r.Values)...))
+	case NotIn:
+		return sqlbuilder.Not(t.exists(r.Key, sqlbuilder.NewColumnCondition(value, "IN", toArgs(r.Values)...)), sqlbuilder.OmitBrackets)
+	default:
+		return t.exists(r.Key, compareInt(expr.Col(value), r))
+	}
+}
+
+func (t LabelTable) exists(key string, value sqlbuilder.Condition) sqlbuilder.Condition {
+	conditions := []sqlbuilder.Condition{
+		expr.Cmp(expr.Col(t.Table+"."+t.ObjectColumn), "=", expr.Col(t.Owner)),
+		sqlbuilder.NewColumnCondition(t.Table+"."+t.KeyColumn, "=", key),
+	}
+	if value != nil {
+		conditions = append(conditions, value)
+	}
+	return sqlbuilder.Exists(sqlbuilder.NewQuery(t.Table).Select("1").Where(conditions...).DQL())
+}
+
+/*
+JSONColumn stores the labels as a JSON object in a column, such as {"env": "prod", "tier": "web"}.
+The requirements are JSON predicates of the dialect, PostgreSQL, MySQL and SQLite are supported.
+The column must be jsonb on PostgreSQL, since Exists and DoesNotExist use the ? operator which json does not have.
+The values are compared as integers by GreaterThan and LessThan, the values which are not integers never match.
+*/
+type JSONColumn struct {
+	// The column, such as "interfaces.labels".
+	Column string
+}
+
+func (c JSONColumn) Condition(r Requirement) sqlbuilder.Condition {
+	if err := r.validate(); err != nil {
+		return invalid(err)
+	}
+	column := expr.Col(c.Column)
+	value := expr.JSONText(column, r.Key)
+	missing := expr.Cmp(value, "IS", expr.Raw("NULL"))
+	switch r.Operator {
+	case Exists:
+		return expr.HasKey(column, r.Key)
+	case DoesNotExist:
+		return sqlbuilder.Not(expr.HasKey(column, r.Key), sqlbuilder.OmitBrackets)
+	case Equals:
+		return expr.Cmp(value, "=", expr.Val(r.Values[0]))
+	case NotEquals:
+		return sqlbuilder.Or(missing, expr.Cmp(value, "<>", expr.Val(r.Values[0])), sqlbuilder.SaveBrackets)
+	case In:
+		return in(value, "IN", r.Values)
+	case NotIn:
+		return sqlbuilder.Or(missing, in(value, "NOT IN", r.Values), sqlbuilder.SaveBrackets)
+	default:
+		return compareInt(value, r)
+	}
+}
+
+// The expression is one or none of the values, such as "e IN (?, ?)".
+func in(e sqlbuilder.Expression, op string, values []string) sqlbuilder.Condition {
+	args := make([]sqlbuilder.Expression, len(values))
+	for i, v := range values {
+		args[i] = expr.Val(v)
+	}
+	// The function without a name is the parenthesized list of values.
+	return expr.Cmp(e, op, expr.Call("", args...))
+}
+
+// Compare the expression as an integer, the type of CAST depends on the dialect.
+// The CAST is guarded by CASE, the values which are not integers are NULL, so they never match rather than fail the query.
+func compareInt(e sqlbuilder.Expression, r Requirement) sqlbuilder.Condition {
+	op := ">"
+	if r.Operator == LessThan {
+		op = "<"
+	}
+	// The value has been validated.
+	value, _ := strconv.ParseInt(r.Values[0], 10, 64)
+	return sqlbuilder.NewCustomCondition(func(sqlWriter io.StringWriter, argWriter sqlbuilder.ArgWriter) error {
+		typ := "INTEGER"
+		dialect := sqlbuilder.DialectOf(argWriter)
+		switch dialect {
+		case sqlbuilder.PostgreSQL:
+			typ = "BIGINT"
+		case sqlbuilder.MySQL:
+			typ = "SIGNED"
+		}
+		guarded := expr.CaseWhen(isInteger(e, dialect), expr.CastAs(e, typ))
+		return expr.Cmp(guarded, op, expr.Val(value)).Parse(sqlWriter, argWriter)
+	})
+}
+
+// The pattern of integers which fit in BIGINT, ? is avoided since the MySQL driver may interpolate it as a placeholder.
+const integerPattern = "'^-{0,1}[0-9]{1,18}$'"
+
+// Whether the text of the expression is an integer, SQLite has no REGEXP by default, so it's checked by GLOB,
+// the first character is a digit or a minus followed by a digit, and the rest are digits.
+func isInteger(e sqlbuilder.Expression, dialect sqlbuilder.Dialect) sqlbuilder.Condition {
+	switch dialect {
+	case sqlbuilder.PostgreSQL:
+		return expr.Cmp(e, "~", expr.Raw(integerPattern))
+	case sqlbuilder.MySQL:
+		return expr.Cmp(e, "REGEXP", expr.Raw(integerPattern))
+	default:
+		head := sqlbuilder.Or(expr.Cmp(e, "GLOB", expr.Raw("'[0-9]*'")), expr.Cmp(e, "GLOB", expr.Raw("'-[0-9]*'")), sqlbuilder.SaveBrackets)
+		return sqlbuilder.And(head, expr.Cmp(e, "NOT GLOB", expr.Raw("'?*[^0-9]*'")), sqlbuilder.OmitBrackets)
+	}
+}
+
+// The condition fails to parse with the error, so that the invalid requirement built manually is reported by Build.
+func invalid(err error) sqlbuilder.Condition {
+	return sqlbuilder.NewCustomCondition(func(io.StringWriter, sqlbuilder.ArgWriter) error {
+		return err
+	})
+}
+
+func toArgs(values []string) []sqlbuilder.Arg {
+	args := make([]sqlbuilder.Arg, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
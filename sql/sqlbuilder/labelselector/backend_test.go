@@ -0,0 +1,125 @@
+package labelselector_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/labelselector"
+	"github.com/everoute/util/sql/sqlbuilder/sqlitetest"
+)
+
+var (
+	labelTable = labelselector.LabelTable{Table: "labels", Owner: "objects.id", ObjectColumn: "object_id", KeyColumn: "key", ValueColumn: "value"}
+	jsonColumn = labelselector.JSONColumn{Column: "objects.labels"}
+)
+
+func render(t *testing.T, selector string, b labelselector.Backend, dialect sqlbuilder.Dialect) (string, []sqlbuilder.Arg, error) {
+	s, err := labelselector.Parse(selector)
+	Expect(err).ShouldNot(HaveOccurred())
+	return sqlbuilder.Build(&sqlbuilder.WhereClause{Conditions: s.Conditions(b)}, dialect, sqlbuilder.Compact)
+}
+
+func TestBackends(t *testing.T) {
+	t.Run("label table", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args, err := render(t, "env=prod,tier notin (web,db),!deprecated,cpu>4", labelTable, sqlbuilder.PostgreSQL)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("WHERE " +
+			"EXISTS ( SELECT 1 FROM labels WHERE labels.object_id = objects.id AND labels.key = $1 AND labels.value = $2 ) " +
+			"AND NOT EXISTS ( SELECT 1 FROM labels WHERE labels.object_id = objects.id AND labels.key = $3 AND labels.value IN ($4, $5) ) " +
+			"AND NOT EXISTS ( SELECT 1 FROM labels WHERE labels.object_id = objects.id AND labels.key = $6 ) " +
+			"AND EXISTS ( SELECT 1 FROM labels WHERE labels.object_id = objects.id AND labels.key = $7 AND CASE WHEN labels.value ~ '^-{0,1}[0-9]{1,18}$' THEN CAST(labels.value AS BIGINT) END > $8 ) "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"env", "prod", "tier", "web", "db", "deprecated", "cpu", int64(4)}))
+
+		s, err := labelselector.Parse("env=prod")
+		Expect(err).ShouldNot(HaveOccurred())
+		tables := sqlbuilder.ReferencedTables(sqlbuilder.NewQuery("objects").Where(s.Conditions(labelTable)...).DQL())
+		Expect(tables).Should(ConsistOf("objects", "labels"))
+	})
+	t.Run("json column", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args, err := render(t, "env=prod,tier notin (web,db),!deprecated,cpu<4", jsonColumn, sqlbuilder.PostgreSQL)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("WHERE objects.labels ->> $1 = $2 " +
+			"AND (objects.labels ->> $3 IS NULL OR objects.labels ->> $4 NOT IN ($5, $6)) " +
+			"AND NOT objects.labels ? $7 " +
+			"AND CASE WHEN objects.labels ->> $8 ~ '^-{0,1}[0-9]{1,18}$' THEN CAST(objects.labels ->> $9 AS BIGINT) END < $10 "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"env", "prod", "tier", "tier", "web", "db", "deprecated", "cpu", "cpu", int64(4)}))
+
+		sql, _, err = render(t, "app.kubernetes.io/name!=nginx,gpu", jsonColumn, sqlbuilder.MySQL)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("WHERE (JSON_UNQUOTE(JSON_EXTRACT(objects.labels, ?)) IS NULL OR JSON_UNQUOTE(JSON_EXTRACT(objects.labels, ?)) <> ?) " +
+			"AND JSON_CONTAINS_PATH(objects.labels, 'one', ?) "))
+
+		sql, _, err = render(t, "cpu>4", jsonColumn, sqlbuilder.MySQL)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("WHERE CASE WHEN JSON_UNQUOTE(JSON_EXTRACT(objects.labels, ?)) REGEXP '^-{0,1}[0-9]{1,18}$' " +
+			"THEN CAST(JSON_UNQUOTE(JSON_EXTRACT(objects.labels, ?)) AS SIGNED) END > ? "))
+
+		sql, _, err = render(t, "cpu>4", jsonColumn, sqlbuilder.SQLite)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("WHERE CASE WHEN (json_extract(objects.labels, ?) GLOB '[0-9]*' OR json_extract(objects.labels, ?) GLOB '-[0-9]*') " +
+			"AND json_extract(objects.labels, ?) NOT GLOB '?*[^0-9]*' THEN CAST(json_extract(objects.labels, ?) AS INTEGER) END > ? "))
+
+		_, _, err = render(t, "gpu", jsonColumn, sqlbuilder.Generic)
+		Expect(err).Should(MatchError(sqlbuilder.ErrUnsupported))
+	})
+	t.Run("invalid requirement", func(t *testing.T) {
+		RegisterTestingT(t)
+		s := labelselector.Selector{{Key: "env", Operator: labelselector.Equals}}
+		_, _, err := sqlbuilder.Build(&sqlbuilder.WhereClause{Conditions: s.Conditions(labelTable)}, sqlbuilder.SQLite, sqlbuilder.Compact)
+		Expect(err).Should(MatchError(`WhereClause.Conditions[0]: invalid values [] of label "env" for operator "="`))
+		Expect(labelselector.Selector(nil).Condition(labelTable)).Should(BeNil())
+	})
+}
+
+func TestSemantics(t *testing.T) {
+	RegisterTestingT(t)
+	objects := map[int64]map[string]string{
+		1: {"env": "prod", "tier": "web", "cpu": "8"},
+		2: {"env": "prod", "tier": "db", "deprecated": "true"},
+		3: {"env": "dev", "cpu": "2", "app.kubernetes.io/name": "nginx"},
+		4: {},
+		5: {"cpu": "many"},
+		6: {"cpu": "-3"},
+		7: {"cpu": "5m"},
+	}
+	db := sqlitetest.Open(t,
+		sqlbuilder.NewSimpleClause(sqlbuilder.DontNewline, "CREATE TABLE objects (id INTEGER PRIMARY KEY, labels TEXT)"),
+		sqlbuilder.NewSimpleClause(sqlbuilder.DontNewline, "CREATE TABLE labels (object_id INTEGER, key TEXT, value TEXT, PRIMARY KEY (object_id, key))"),
+	)
+	for id, labels := range objects {
+		json := "{"
+		for k, v := range labels {
+			if len(json) > 1 {
+				json += ","
+			}
+			json += `"` + k + `":"` + v + `"`
+			db.MustInsert("labels", []string{"object_id", "key", "value"}, []sqlbuilder.Arg{id, k, v})
+		}
+		db.MustInsert("objects", []string{"id", "labels"}, []sqlbuilder.Arg{id, json + "}"})
+	}
+	for selector, expected := range map[string][]any{
+		"":                            {int64(1), int64(2), int64(3), int64(4), int64(5), int64(6), int64(7)},
+		"env=prod":                    {int64(1), int64(2)},
+		"env!=prod":                   {int64(3), int64(4), int64(5), int64(6), int64(7)},
+		"tier in (web,db)":            {int64(1), int64(2)},
+		"tier notin (web)":            {int64(2), int64(3), int64(4), int64(5), int64(6), int64(7)},
+		"env=prod,!deprecated":        {int64(1)},
+		"cpu>4":                       {int64(1)},
+		"cpu<4,env":                   {int64(3)},
+		"cpu<4":                       {int64(3), int64(6)},
+		"cpu>-10":                     {int64(1), int64(3), int64(6)},
+		"app.kubernetes.io/name":      {int64(3)},
+		"env,!app.kubernetes.io/name": {int64(1), int64(2)},
+	} {
+		s, err := labelselector.Parse(selector)
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, b := range []labelselector.Backend{labelTable, jsonColumn} {
+			res := db.MustQuery(sqlbuilder.NewQuery("objects").Select("id").Where(s.Condition(b)).OrderBy("id"))
+			Expect(res.Column("id")).Should(Equal(expected), "%q with %T", selector, b)
+		}
+	}
+}
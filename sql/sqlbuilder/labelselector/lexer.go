@@ -0,0 +1,70 @@
+package labelselector
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF  tokenKind = iota
+	tokenWord           // Keys, values and the operators in and notin.
+	tokenOp             // Such as =, ==, !=, !, > and <.
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// The byte offset of the token in the selector.
+	offset int
+}
+
+var operators = []string{"==", "!=", "=", "!", ">", "<"}
+
+// Split the selector into tokens, the last token is always tokenEOF.
+func lex(selector string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(selector); {
+		r, size := utf8.DecodeRuneInString(selector[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", offset: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", offset: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", offset: i})
+			i++
+		case strings.ContainsRune("=!<>", r):
+			for _, op := range operators {
+				if strings.HasPrefix(selector[i:], op) {
+					tokens = append(tokens, token{kind: tokenOp, text: op, offset: i})
+					i += len(op)
+					break
+				}
+			}
+		case isWordChar(r):
+			end := i
+			for end < len(selector) && isWordChar(rune(selector[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: selector[i:end], offset: i})
+			i = end
+		default:
+			return nil, newError(i, "unexpected %q", r)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, offset: len(selector)}), nil
+}
+
+func isWordChar(r rune) bool {
+	return r < utf8.RuneSelf && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./", r))
+}
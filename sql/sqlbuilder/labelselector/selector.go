@@ -0,0 +1,295 @@
+/*
+Package labelselector converts the label selectors of Kubernetes style into sqlbuilder conditions, such as:
+
+	env=prod,tier in (web,db),!deprecated
+
+The requirements are separated by commas and all of them must be matched:
+
+	key=value, key==value  the label is the value
+	key!=value             the label is not the value or the label does not exist
+	key in (v1,v2)         the label is one of the values
+	key notin (v1,v2)      the label is none of the values or the label does not exist
+	key                    the label exists
+	!key                   the label does not exist
+	key>1, key<1           the label is an integer greater or less than the value
+
+The conditions are produced by a Backend, such as LabelTable for a table of labels, or JSONColumn for a JSON column of label maps.
+The keys and values are always bound as arguments.
+*/
+package labelselector
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+type Operator string
+
+const (
+	Equals       Operator = "="
+	NotEquals    Operator = "!="
+	In           Operator = "in"
+	NotIn        Operator = "notin"
+	Exists       Operator = "exists"
+	DoesNotExist Operator = "!"
+	GreaterThan  Operator = "gt"
+	LessThan     Operator = "lt"
+)
+
+// Requirement is a requirement of the selector, such as "tier in (web,db)".
+type Requirement struct {
+	Key      string
+	Operator Operator
+	// One value for Equals and NotEquals, an integer for GreaterThan and LessThan,
+	// at least one value for In and NotIn, and no value for Exists and DoesNotExist.
+	Values []string
+}
+
+func (r Requirement) String() string {
+	switch r.Operator {
+	case Exists:
+		return r.Key
+	case DoesNotExist:
+		return "!" + r.Key
+	case In, NotIn:
+		return r.Key + " " + string(r.Operator) + " (" + strings.Join(r.Values, ",") + ")"
+	case GreaterThan:
+		return r.Key + ">" + strings.Join(r.Values, ",")
+	case LessThan:
+		return r.Key + "<" + strings.Join(r.Values, ",")
+	default:
+		return r.Key + string(r.Operator) + strings.Join(r.Values, ",")
+	}
+}
+
+// Check the number of values for the operator, the requirements built manually may be invalid.
+func (r Requirement) validate() error {
+	var ok bool
+	switch r.Operator {
+	case Exists, DoesNotExist:
+		ok = len(r.Values) == 0
+	case Equals, NotEquals:
+		ok = len(r.Values) == 1
+	case In, NotIn:
+		ok = len(r.Values) != 0
+	case GreaterThan, LessThan:
+		if ok = len(r.Values) == 1; ok {
+			_, err := strconv.ParseInt(r.Values[0], 10, 64)
+			ok = err == nil
+		}
+	default:
+		return sqlbuilder.NewInvalidError("unknown operator %q of label %q", r.Operator, r.Key)
+	}
+	if !ok {
+		return sqlbuilder.NewInvalidError("invalid values %q of label %q for operator %q", r.Values, r.Key, r.Operator)
+	}
+	return nil
+}
+
+// Selector is the requirements in the order of the selector string, an empty selector matches everything.
+type Selector []Requirement
+
+func (s Selector) String() string {
+	strs := make([]string, len(s))
+	for i, r := range s {
+		strs[i] = r.String()
+	}
+	return strings.Join(strs, ",")
+}
+
+// Backend produces the condition of a requirement, it depends on how the labels are stored.
+type Backend interface {
+	Condition(r Requirement) sqlbuilder.Condition
+}
+
+// Get the conditions of the requirements, they can be used in WhereClause directly.
+func (s Selector) Conditions(b Backend) []sqlbuilder.Condition {
+	res := make([]sqlbuilder.Condition, len(s))
+	for i, r := range s {
+		res[i] = b.Condition(r)
+	}
+	return res
+}
+
+// Get the conditions of the requirements joined by AND, it's nil if the selector is empty.
+func (s Selector) Condition(b Backend) sqlbuilder.Condition {
+	var res sqlbuilder.Condition
+	for _, r := range s {
+		res = sqlbuilder.And(res, b.Condition(r), sqlbuilder.OmitBrackets)
+	}
+	return res
+}
+
+// Error is a user-facing error of the selector, it's safe to return it to the clients of API.
+type Error struct {
+	// The byte offset in the selector where the error occurs.
+	Offset int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Offset+1)
+}
+
+func newError(offset int, format string, args ...any) *Error {
+	return &Error{Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+var (
+	// The name of keys and the values, a value can also be empty.
+	namePattern = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+	// The optional prefix of keys, such as "app.kubernetes.io".
+	prefixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+const maxPrefixLength = 253
+
+// Parse the selector, the error is an *Error.
+func Parse(selector string) (Selector, error) {
+	tokens, err := lex(selector)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	var res Selector
+	if p.peek().kind == tokenEOF {
+		return res, nil
+	}
+	for {
+		r, err := p.requirement()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+		switch t := p.next(); t.kind {
+		case tokenEOF:
+			return res, nil
+		case tokenComma:
+		default:
+			return nil, unexpected(t, `","`)
+		}
+	}
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func unexpected(t token, expected string) *Error {
+	if t.kind == tokenEOF {
+		return newError(t.offset, "expected %s, but the selector ends", expected)
+	}
+	return newError(t.offset, "expected %s, but got %q", expected, t.text)
+}
+
+func (p *parser) requirement() (Requirement, error) {
+	if t := p.peek(); t.kind == tokenOp && t.text == "!" {
+		p.pos++
+		key, err := p.key()
+		return Requirement{Key: key, Operator: DoesNotExist}, err
+	}
+	key, err := p.key()
+	if err != nil {
+		return Requirement{}, err
+	}
+	t := p.peek()
+	switch {
+	case t.kind == tokenComma || t.kind == tokenEOF:
+		return Requirement{Key: key, Operator: Exists}, nil
+	case t.kind == tokenWord && (t.text == "in" || t.text == "notin"):
+		p.pos++
+		values, err := p.values()
+		return Requirement{Key: key, Operator: Operator(t.text), Values: values}, err
+	case t.kind != tokenOp || t.text == "!":
+		return Requirement{}, unexpected(t, "an operator")
+	}
+	p.pos++
+	op := map[string]Operator{"=": Equals, "==": Equals, "!=": NotEquals, ">": GreaterThan, "<": LessThan}[t.text]
+	v := p.peek()
+	value := ""
+	if v.kind == tokenWord {
+		p.pos++
+		value = v.text
+	}
+	if op == GreaterThan || op == LessThan {
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return Requirement{}, newError(v.offset, "expected an integer, but got %q", value)
+		}
+	} else if err := validateValue(v.offset, value); err != nil {
+		return Requirement{}, err
+	}
+	return Requirement{Key: key, Operator: op, Values: []string{value}}, nil
+}
+
+func (p *parser) key() (string, error) {
+	t := p.next()
+	if t.kind != tokenWord {
+		return "", unexpected(t, "a key")
+	}
+	name := t.text
+	if i := strings.LastIndexByte(t.text, '/'); i >= 0 {
+		prefix := t.text[:i]
+		if len(prefix) > maxPrefixLength || !prefixPattern.MatchString(prefix) {
+			return "", newError(t.offset, "invalid prefix %q of key", prefix)
+		}
+		name = t.text[i+1:]
+	}
+	if !namePattern.MatchString(name) {
+		return "", newError(t.offset, "invalid key %q", t.text)
+	}
+	return t.text, nil
+}
+
+func (p *parser) values() ([]string, error) {
+	if t := p.next(); t.kind != tokenLParen {
+		return nil, unexpected(t, `"("`)
+	}
+	// An empty set matches nothing, it's rejected like Kubernetes, but an empty value can be listed as "key in (,a)".
+	if t := p.peek(); t.kind == tokenRParen {
+		return nil, unexpected(t, "a value")
+	}
+	var values []string
+	for {
+		v := p.peek()
+		value := ""
+		if v.kind == tokenWord {
+			p.pos++
+			value = v.text
+		}
+		if err := validateValue(v.offset, value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		switch t := p.next(); t.kind {
+		case tokenRParen:
+			return values, nil
+		case tokenComma:
+		default:
+			return nil, unexpected(t, `"," or ")"`)
+		}
+	}
+}
+
+func validateValue(offset int, value string) error {
+	if value != "" && !namePattern.MatchString(value) {
+		return newError(offset, "invalid value %q", value)
+	}
+	return nil
+}
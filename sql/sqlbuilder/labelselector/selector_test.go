@@ -0,0 +1,59 @@
+package labelselector_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder/labelselector"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("requirements", func(t *testing.T) {
+		RegisterTestingT(t)
+		s, err := labelselector.Parse("env=prod, tier in (web,db),!deprecated,app.kubernetes.io/name==nginx,zone notin (a, ),owner!=,gpu,cpu>4,mem<-1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(s).Should(Equal(labelselector.Selector{
+			{Key: "env", Operator: labelselector.Equals, Values: []string{"prod"}},
+			{Key: "tier", Operator: labelselector.In, Values: []string{"web", "db"}},
+			{Key: "deprecated", Operator: labelselector.DoesNotExist},
+			{Key: "app.kubernetes.io/name", Operator: labelselector.Equals, Values: []string{"nginx"}},
+			{Key: "zone", Operator: labelselector.NotIn, Values: []string{"a", ""}},
+			{Key: "owner", Operator: labelselector.NotEquals, Values: []string{""}},
+			{Key: "gpu", Operator: labelselector.Exists},
+			{Key: "cpu", Operator: labelselector.GreaterThan, Values: []string{"4"}},
+			{Key: "mem", Operator: labelselector.LessThan, Values: []string{"-1"}},
+		}))
+		Expect(s.String()).Should(Equal("env=prod,tier in (web,db),!deprecated,app.kubernetes.io/name=nginx,zone notin (a,),owner!=,gpu,cpu>4,mem<-1"))
+	})
+	t.Run("empty", func(t *testing.T) {
+		RegisterTestingT(t)
+		s, err := labelselector.Parse(" ")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(s).Should(BeEmpty())
+	})
+	t.Run("errors", func(t *testing.T) {
+		RegisterTestingT(t)
+		for selector, msg := range map[string]string{
+			"env=prod,":        "expected a key, but the selector ends at position 10",
+			"env prod":         `expected an operator, but got "prod" at position 5`,
+			"env in prod":      `expected "(", but got "prod" at position 8`,
+			"env in (a b)":     `expected "," or ")", but got "b" at position 11`,
+			"env in (a":        `expected "," or ")", but the selector ends at position 10`,
+			"env in ()":        `expected a value, but got ")" at position 9`,
+			"-env=prod":        `invalid key "-env" at position 1`,
+			"Example.com/a=b":  `invalid prefix "Example.com" of key at position 1`,
+			"env=-prod":        `invalid value "-prod" at position 5`,
+			"cpu>four":         `expected an integer, but got "four" at position 5`,
+			"env=prod;tier=db": `unexpected ';' at position 9`,
+			"env!":             `expected an operator, but got "!" at position 4`,
+			"env=a)":           `expected ",", but got ")" at position 6`,
+		} {
+			_, err := labelselector.Parse(selector)
+			Expect(err).Should(MatchError(msg), selector)
+			var e *labelselector.Error
+			Expect(errors.As(err, &e)).Should(BeTrue())
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package sqlbuilder
+
+import (
+	"io"
+	"strings"
+)
+
+// The strength of the row lock.
+type LockStrength string
+
+const (
+	ForUpdate LockStrength = "UPDATE"
+	ForShare  LockStrength = "SHARE"
+	// NO KEY UPDATE and KEY SHARE are supported by PostgreSQL only.
+	ForNoKeyUpdate LockStrength = "NO KEY UPDATE"
+	ForKeyShare    LockStrength = "KEY SHARE"
+)
+
+// The behavior when the rows are locked by others.
+type LockWait int
+
+const (
+	Wait       LockWait = iota // Wait until the rows are unlocked.
+	NoWait                     // Fail immediately.
+	SkipLocked                 // Skip the locked rows, such as a job queue.
+)
+
+/*
+Lock is the locking clause of DQL, such as "FOR UPDATE OF jobs SKIP LOCKED".
+It's placed after LIMIT which is required by MySQL and accepted by PostgreSQL.
+SQLite does not support row locking, the DQL with a valid Lock is rejected.
+*/
+type Lock struct {
+	Strength LockStrength
+	// Lock the rows of the tables only, all tables are locked if it's empty.
+	Of   []string
+	Wait LockWait
+}
+
+func (c Lock) Valid() bool {
+	return c.Strength != ""
+}
+
+func (c Lock) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	dialect := DialectOf(argWriter)
+	switch c.Strength {
+	case ForUpdate, ForShare:
+	case ForNoKeyUpdate, ForKeyShare:
+		if dialect == MySQL {
+			return NewUnsupportedError(dialect, "FOR "+string(c.Strength))
+		}
+	default:
+		return NewInvalidError("unknown lock strength %q", c.Strength)
+	}
+	if dialect == SQLite {
+		return NewUnsupportedError(dialect, "FOR "+string(c.Strength))
+	}
+	var wait string
+	switch c.Wait {
+	case Wait:
+	case NoWait:
+		wait = " NOWAIT"
+	case SkipLocked:
+		wait = " SKIP LOCKED"
+	default:
+		return NewInvalidError("unknown lock wait %d", c.Wait)
+	}
+	err := WriteSpace(sqlWriter, level)
+	if err != nil {
+		return err
+	}
+	err = WriteKeyword(sqlWriter, "FOR "+string(c.Strength))
+	if err != nil {
+		return err
+	}
+	if len(c.Of) != 0 {
+		err = WriteKeyword(sqlWriter, " OF ")
+		if err != nil {
+			return err
+		}
+		err = WriteString(sqlWriter, strings.Join(c.Of, ", "))
+		if err != nil {
+			return err
+		}
+	}
+	err = WriteKeyword(sqlWriter, wait)
+	if err != nil {
+		return err
+	}
+	return EndLine(sqlWriter, CompactLevel(level))
+}
+
+func (c Lock) Clone() Lock {
+	c.Of = cloneStrings(c.Of)
+	return c
+}
@@ -0,0 +1,52 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestLock(t *testing.T) {
+	t.Run("job queue", func(t *testing.T) {
+		RegisterTestingT(t)
+		q := sqlbuilder.NewQuery("jobs").
+			Select("id").
+			Where(sqlbuilder.NewColumnCondition("state", "=", "pending")).
+			OrderBy("id").
+			Limit("?", 10).
+			Lock(sqlbuilder.Lock{Strength: sqlbuilder.ForUpdate, Of: []string{"jobs"}, Wait: sqlbuilder.SkipLocked})
+		for _, dialect := range []sqlbuilder.Dialect{sqlbuilder.PostgreSQL, sqlbuilder.MySQL} {
+			sql, args, err := sqlbuilder.Build(q, dialect, sqlbuilder.Format)
+			Expect(err).Should(Succeed())
+			Expect(sql).Should(HaveSuffix("ORDER BY id\nLIMIT ?\nFOR UPDATE OF jobs SKIP LOCKED\n"))
+			Expect(args).Should(Equal([]sqlbuilder.Arg{"pending", 10}))
+		}
+		_, _, err := sqlbuilder.Build(q, sqlbuilder.SQLite, sqlbuilder.Format)
+		Expect(err).Should(MatchError("Query.Lock: FOR UPDATE is not supported by sqlite"))
+	})
+	t.Run("options", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, _, err := sqlbuilder.Build(sqlbuilder.Lock{Strength: sqlbuilder.ForShare, Wait: sqlbuilder.NoWait}, sqlbuilder.MySQL, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("FOR SHARE NOWAIT "))
+		sql, _, err = sqlbuilder.Build(sqlbuilder.Lock{Strength: sqlbuilder.ForNoKeyUpdate}, sqlbuilder.PostgreSQL, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("FOR NO KEY UPDATE "))
+		_, _, err = sqlbuilder.Build(sqlbuilder.Lock{Strength: sqlbuilder.ForKeyShare}, sqlbuilder.MySQL, sqlbuilder.Compact)
+		Expect(err).Should(MatchError("Lock: FOR KEY SHARE is not supported by mysql"))
+		_, _, err = sqlbuilder.Build(sqlbuilder.Lock{Strength: "ALL"}, sqlbuilder.MySQL, sqlbuilder.Compact)
+		Expect(err).Should(MatchError(`Lock: unknown lock strength "ALL"`))
+	})
+	t.Run("clone", func(t *testing.T) {
+		RegisterTestingT(t)
+		dql := &sqlbuilder.DQL{From: sqlbuilder.FromTableName("jobs"), Lock: sqlbuilder.Lock{Strength: sqlbuilder.ForUpdate, Of: []string{"jobs"}}}
+		c := dql.Clone()
+		c.Lock.Of[0] = "others"
+		Expect(dql.Lock.Of).Should(Equal([]string{"jobs"}))
+		sql, _, err := sqlbuilder.Build(&sqlbuilder.DQL{From: sqlbuilder.FromTableName("jobs")}, sqlbuilder.SQLite, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT * FROM jobs "))
+	})
+}
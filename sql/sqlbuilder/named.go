@@ -0,0 +1,180 @@
+package sqlbuilder
+
+import (
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The tag of struct field to specify the name of a named argument.
+const NamedArgTag = "db"
+
+var errMixedArgs = NewInvalidError("positional and named arguments can not be mixed")
+
+/*
+Write the SQL with named placeholders such as :name or @name.
+The placeholders will be replaced with the positional style of the dialect carried by argWriter,
+and the arguments will be written in the same order as the placeholders.
+namedArgs: A map[string]T or a struct(or a pointer of struct),
+the name of a struct field is the value of the tag "db", or the field name if the tag is absent.
+Placeholders in quotes and comments are ignored, "::" and "@@" are not placeholders.
+It returns an error if a name is missing in namedArgs, or a key of the map is unused.
+*/
+func WriteNamed(sqlWriter io.StringWriter, argWriter ArgWriter, str string, namedArgs any) error {
+	lookup, keys, err := namedArgsLookup(namedArgs)
+	if err != nil {
+		return err
+	}
+	var (
+		builder strings.Builder
+		args    []Arg
+		used    = make(map[string]bool)
+	)
+	builder.Grow(len(str))
+	base := 0
+	if w, ok := argWriter.(DialectArgWriter); ok {
+		base = w.Len()
+	}
+	dialect := DialectOf(argWriter)
+	for i := 0; i < len(str); {
+		if end := skipLiteral(str, i); end > i {
+			builder.WriteString(str[i:end])
+			i = end
+			continue
+		}
+		ch := str[i]
+		switch {
+		case (ch == ':' || ch == '@') && i+1 < len(str) && str[i+1] == ch:
+			builder.WriteString(str[i : i+2])
+			i += 2
+		case (ch == ':' || ch == '@') && i+1 < len(str) && isNameStart(str[i+1]):
+			end := i + 2
+			for end < len(str) && isNamePart(str[end]) {
+				end++
+			}
+			name := str[i+1 : end]
+			arg, ok := lookup(name)
+			if !ok {
+				return NewInvalidError("named argument %q is missing", name)
+			}
+			used[name] = true
+			args = append(args, arg)
+			builder.WriteString(dialect.Placeholder(base + len(args)))
+			i = end
+		default:
+			builder.WriteByte(ch)
+			i++
+		}
+	}
+	for _, key := range keys {
+		if !used[key] {
+			return NewInvalidError("named argument %q is unused", key)
+		}
+	}
+	if err = WriteString(sqlWriter, builder.String()); err != nil {
+		return err
+	}
+	return WriteArgs(argWriter, args...)
+}
+
+// Returns the index after the quoted string or comment begins at str[begin], or begin if there is none.
+func skipLiteral(str string, begin int) int {
+	switch ch := str[begin]; {
+	case ch == '\'' || ch == '"' || ch == '`':
+		return skipQuoted(str, begin)
+	case strings.HasPrefix(str[begin:], "--"):
+		end := strings.IndexByte(str[begin:], '\n')
+		if end < 0 {
+			return len(str)
+		}
+		return begin + end
+	case strings.HasPrefix(str[begin:], "/*"):
+		end := strings.Index(str[begin+2:], "*/")
+		if end < 0 {
+			return len(str)
+		}
+		return begin + end + 4
+	default:
+		return begin
+	}
+}
+
+// Returns the index after the closing quote of the quoted string begins at str[begin].
+func skipQuoted(str string, begin int) int {
+	quote := str[begin]
+	for i := begin + 1; i < len(str); i++ {
+		if str[i] == quote {
+			// Two quotes is an escaped quote.
+			if i+1 < len(str) && str[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(str)
+}
+
+func isNameStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isNamePart(ch byte) bool {
+	return isNameStart(ch) || (ch >= '0' && ch <= '9')
+}
+
+// Returns a function to lookup the argument by name, and the keys must be used.
+func namedArgsLookup(namedArgs any) (func(name string) (Arg, bool), []string, error) {
+	value := reflect.ValueOf(namedArgs)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, nil, NewInvalidError("named arguments is a nil pointer")
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return nil, nil, NewInvalidError("the key of named arguments must be string, but got %s", value.Type().Key())
+		}
+		keys := make([]string, 0, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			keys = append(keys, iter.Key().String())
+		}
+		sort.Strings(keys)
+		return func(name string) (Arg, bool) {
+			v := value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+			if !v.IsValid() {
+				return nil, false
+			}
+			return v.Interface(), true
+		}, keys, nil
+	case reflect.Struct:
+		fields := make(map[string]int, value.NumField())
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Tag.Get(NamedArgTag)
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			fields[name] = i
+		}
+		return func(name string) (Arg, bool) {
+			i, ok := fields[name]
+			if !ok {
+				return nil, false
+			}
+			return value.Field(i).Interface(), true
+		}, nil, nil
+	default:
+		return nil, nil, NewInvalidError("named arguments must be a map or struct, but got %T", namedArgs)
+	}
+}
@@ -0,0 +1,112 @@
+package sqlbuilder_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestNamedCondition(t *testing.T) {
+	t.Run("map", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := sqlbuilder.NewNamedCondition("a = :a AND b = @b AND c = :a", map[string]any{"a": 1, "b": "2"})
+		buff := bytes.NewBufferString("")
+		var argWriter = NewArgWriter(0)
+		err := c.Parse(buff, argWriter)
+		Expect(err).Should(Succeed())
+		Expect(buff.String()).To(Equal("a = ? AND b = ? AND c = ?"))
+		Expect(argWriter.Args).To(Equal([]sqlbuilder.Arg{1, "2", 1}))
+	})
+	t.Run("struct", func(t *testing.T) {
+		RegisterTestingT(t)
+		type filter struct {
+			Name string `db:"name"`
+			MTU  int
+			Skip string `db:"-"`
+		}
+		c := sqlbuilder.NewNamedCondition("name = :name AND mtu > :MTU", &filter{Name: "eth0", MTU: 1500})
+		buff := bytes.NewBufferString("")
+		var argWriter = NewArgWriter(0)
+		err := c.Parse(buff, argWriter)
+		Expect(err).Should(Succeed())
+		Expect(buff.String()).To(Equal("name = ? AND mtu > ?"))
+		Expect(argWriter.Args).To(Equal([]sqlbuilder.Arg{"eth0", 1500}))
+	})
+	t.Run("positional dialect", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := sqlbuilder.NewNamedCondition("a = :a AND b = :b", map[string]int{"a": 1, "b": 2})
+		buff := bytes.NewBufferString("")
+		argWriter := sqlbuilder.NewArgList(sqlbuilder.PostgreSQL, 0)
+		Expect(argWriter.WriteArg(0)).Should(Succeed())
+		err := c.Parse(buff, argWriter)
+		Expect(err).Should(Succeed())
+		Expect(buff.String()).To(Equal("a = $2 AND b = $3"))
+		Expect(argWriter.Args).To(Equal([]sqlbuilder.Arg{0, 1, 2}))
+	})
+	t.Run("ignore quotes, comments and casts", func(t *testing.T) {
+		RegisterTestingT(t)
+		str := "a = ':x' AND b = \"@x\" AND c = :x::int AND @@version -- :x\n/* @x */"
+		c := sqlbuilder.NewNamedCondition(str, map[string]any{"x": 1})
+		buff := bytes.NewBufferString("")
+		var argWriter = NewArgWriter(0)
+		err := c.Parse(buff, argWriter)
+		Expect(err).Should(Succeed())
+		Expect(buff.String()).To(Equal("a = ':x' AND b = \"@x\" AND c = ?::int AND @@version -- :x\n/* @x */"))
+		Expect(argWriter.Args).To(Equal([]sqlbuilder.Arg{1}))
+	})
+	t.Run("missing name", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := sqlbuilder.NewNamedCondition("a = :a AND b = :b", map[string]any{"a": 1})
+		err := c.Parse(bytes.NewBufferString(""), NewArgWriter(0))
+		Expect(err).Should(MatchError(ContainSubstring(`"b" is missing`)))
+	})
+	t.Run("unused name", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := sqlbuilder.NewNamedCondition("a = :a", map[string]any{"a": 1, "b": 2})
+		err := c.Parse(bytes.NewBufferString(""), NewArgWriter(0))
+		Expect(err).Should(MatchError(ContainSubstring(`"b" is unused`)))
+	})
+	t.Run("mixed arguments", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := sqlbuilder.SimpleCondition{Str: "a = :a", Args: []sqlbuilder.Arg{1}, NamedArgs: map[string]any{"a": 1}}
+		err := c.Parse(bytes.NewBufferString(""), NewArgWriter(0))
+		Expect(err).ShouldNot(Succeed())
+	})
+	t.Run("bad named arguments", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := sqlbuilder.NewNamedCondition("a = :a", []int{1})
+		err := c.Parse(bytes.NewBufferString(""), NewArgWriter(0))
+		Expect(err).ShouldNot(Succeed())
+	})
+}
+
+func TestNamedClause(t *testing.T) {
+	t.Run("auto new line", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := sqlbuilder.NewNamedClause(sqlbuilder.AutoNewline, "LIMIT :limit OFFSET :offset", map[string]any{"limit": 10, "offset": 20})
+		buff := bytes.NewBufferString("")
+		var argWriter = NewArgWriter(0)
+		err := c.Parse(buff, argWriter, 1)
+		Expect(err).Should(Succeed())
+		Expect(buff.String()).To(Equal("  LIMIT ? OFFSET ?\n"))
+		Expect(argWriter.Args).To(Equal([]sqlbuilder.Arg{10, 20}))
+	})
+	t.Run("compact", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := sqlbuilder.NewNamedClause(sqlbuilder.AutoNewline, "LIMIT :limit", map[string]any{"limit": 10})
+		buff := bytes.NewBufferString("")
+		var argWriter = NewArgWriter(0)
+		err := c.Parse(buff, argWriter, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(buff.String()).To(Equal("LIMIT ? "))
+	})
+	t.Run("on error", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := sqlbuilder.NewNamedClause(sqlbuilder.AutoNewline, "LIMIT :limit", map[string]any{"limit": 10})
+		err := c.Parse(newFixedBuilder(2), NewArgWriter(0), 0)
+		Expect(err).ShouldNot(Succeed())
+	})
+}
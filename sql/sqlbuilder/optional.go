@@ -0,0 +1,60 @@
+package sqlbuilder
+
+// The helpers build optional conditions and clauses, they return nil instead of a condition or clause if it's absent.
+// The nil values are skipped by And, Or, Not, Bracket, WHERE, HAVING, JOIN ON and Clauses, such as:
+//
+//	q.Where(
+//		IfNotZero(req.Name, func(name string) Condition { return NewColumnCondition("name", "LIKE", name+"%") }),
+//		If(req.OnlyUp, NewCondition("state = 'up'")),
+//	)
+
+// Get the condition if ok is true, or nil.
+func If(ok bool, c Condition) Condition {
+	if !ok {
+		return nil
+	}
+	return c
+}
+
+// Get the condition built from the value if it's not the zero value, or nil.
+func IfNotZero[T comparable](value T, fn func(v T) Condition) Condition {
+	var zero T
+	if value == zero {
+		return nil
+	}
+	return fn(value)
+}
+
+// Get the condition built from the values if they are not empty, or nil, such as the condition with IN.
+func IfNotEmpty[T any](values []T, fn func(v []T) Condition) Condition {
+	if len(values) == 0 {
+		return nil
+	}
+	return fn(values)
+}
+
+// Get the condition built from the value which the pointer points to if it's not nil, or nil.
+func IfNotNil[T any](value *T, fn func(v T) Condition) Condition {
+	if value == nil {
+		return nil
+	}
+	return fn(*value)
+}
+
+// Get the clause if ok is true, or nil, such as OptionalClause(req.Limit > 0, MakeLimit("?", req.Limit)) for Group, Order and Limit.
+func OptionalClause(ok bool, c Clause) Clause {
+	if !ok {
+		return nil
+	}
+	return c
+}
+
+// Whether there is any condition which is not nil.
+func hasConditions(conditions []Condition) bool {
+	for _, c := range conditions {
+		if c != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+type filter struct {
+	Name   string
+	Up     bool
+	IDs    []int
+	MinMTU *int
+	Limit  int
+}
+
+func (f filter) query() sqlbuilder.Query {
+	q := sqlbuilder.NewQuery("interfaces").Where(
+		sqlbuilder.IfNotZero(f.Name, func(name string) sqlbuilder.Condition {
+			return sqlbuilder.NewColumnCondition("name", "LIKE", name+"%")
+		}),
+		sqlbuilder.If(f.Up, sqlbuilder.NewCondition("state = 'up'")),
+		sqlbuilder.IfNotEmpty(f.IDs, func(ids []int) sqlbuilder.Condition {
+			return sqlbuilder.NewColumn[int]("", "id").In(ids...)
+		}),
+		sqlbuilder.Or(sqlbuilder.IfNotNil(f.MinMTU, func(mtu int) sqlbuilder.Condition {
+			return sqlbuilder.NewColumnCondition("mtu", ">=", mtu)
+		}), nil, sqlbuilder.SaveBrackets),
+	)
+	dql := q.DQL()
+	dql.Limit = sqlbuilder.OptionalClause(f.Limit > 0, sqlbuilder.MakeLimit("?", f.Limit))
+	dql.Additional = sqlbuilder.Clauses{nil, sqlbuilder.OptionalClause(false, sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "FOR UPDATE"))}
+	return sqlbuilder.QueryOf(dql)
+}
+
+func TestOptional(t *testing.T) {
+	t.Run("absent", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args, err := sqlbuilder.Build(filter{}.query(), sqlbuilder.PostgreSQL, sqlbuilder.Format)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("SELECT *\nFROM interfaces\n"))
+		Expect(args).Should(BeEmpty())
+		Expect(filter{}.query().DQL().Clauses()).Should(HaveLen(3))
+	})
+	t.Run("present", func(t *testing.T) {
+		RegisterTestingT(t)
+		mtu := 1500
+		f := filter{Name: "eth", Up: true, IDs: []int{1, 2}, MinMTU: &mtu, Limit: 10}
+		sql, args, err := sqlbuilder.Build(f.query(), sqlbuilder.MySQL, sqlbuilder.Compact)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("SELECT * FROM interfaces WHERE name LIKE ? AND state = 'up' AND id IN (?, ?) AND (mtu >= ?) LIMIT ? "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"eth%", 1, 2, 1500, 10}))
+	})
+	t.Run("partially present", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, args, err := sqlbuilder.Build(filter{Up: true}.query(), sqlbuilder.Generic, sqlbuilder.Format)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("SELECT *\nFROM interfaces\nWHERE\n  state = 'up'\n"))
+		Expect(args).Should(BeEmpty())
+	})
+	t.Run("nil safe", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(sqlbuilder.Not(nil, sqlbuilder.SaveBrackets)).Should(BeNil())
+		Expect(sqlbuilder.Bracket(nil)).Should(BeNil())
+		Expect(sqlbuilder.And(sqlbuilder.If(false, sqlbuilder.NewCondition("a")), nil, sqlbuilder.SaveBrackets)).Should(BeNil())
+
+		join := &sqlbuilder.Join{Kind: "JOIN", Table: sqlbuilder.TableByName("b"), On: []sqlbuilder.Condition{nil, sqlbuilder.NewCondition("a.id = b.id"), nil}}
+		sql, _, err := sqlbuilder.Build(join, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("JOIN b ON a.id = b.id "))
+		join.On = []sqlbuilder.Condition{nil}
+		sql, _, err = sqlbuilder.Build(join, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("JOIN b "))
+	})
+}
@@ -0,0 +1,210 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// ScopeCondition is the condition appended by RowPolicy, it marks that the statement is scoped for the Table.
+type ScopeCondition struct {
+	Table     string
+	Condition Condition
+}
+
+func (c ScopeCondition) Parse(sqlWriter io.StringWriter, argWriter ArgWriter) error {
+	return Locate(c.Condition.Parse(sqlWriter, argWriter), "Condition")
+}
+
+/*
+RowPolicy scopes the rows of protected tables, such as appending "tenant_id = ?" for every statement.
+The statements are the DQL, UPDATE and DELETE in the tree, including sub queries and common table expressions.
+The scope conditions of joined tables are appended to the ON of JOIN, they should be qualified by the table name.
+The tables of FROM, JOIN, UPDATE and DELETE must be plain names or DQL sub queries, the policy fails closed:
+Check returns an error for the others such as "devices AS d", "public.devices" and SimpleClause,
+because they may reference protected tables which can not be resolved.
+So are the clauses in the positions of statements, they must be DQL, UPDATE or DELETE, such as the tables of WITH,
+the sub queries of SubQuery and EXISTS, Insert.Query and Additional, "UNION SELECT * FROM devices" in a SimpleClause is refused.
+Only the set operators such as "UNION ALL" are allowed as SimpleClause in Additional, they join the statements around them.
+Note: The SQL strings of conditions such as SimpleCondition can not be checked, don't reference protected tables in them.
+*/
+type RowPolicy struct {
+	scopes map[string]Condition
+}
+
+func NewRowPolicy() *RowPolicy {
+	return &RowPolicy{
+		scopes: make(map[string]Condition),
+	}
+}
+
+// Protect the table with the scope condition, such as NewCondition("tenant_id = ?", tenantID).
+func (p *RowPolicy) Protect(table string, scope Condition) *RowPolicy {
+	p.scopes[table] = scope
+	return p
+}
+
+// Append the scope conditions to the WHERE clause of statements referencing protected tables.
+// A statement already scoped will not be scoped again, and the statements are modified in place, see Apply.
+// The conditions are copied before appending, so the slices shared with the caller are not changed.
+// The tables can not be resolved are skipped, and they are refused by Check.
+func (p *RowPolicy) Apply(root Clause) (Clause, error) {
+	return ApplyClause(root, nil, func(cursor *Cursor) bool {
+		switch n := cursor.Node().(type) {
+		case *Join:
+			table, err := resolveTable(n.Table)
+			if err != nil {
+				break
+			}
+			if scope, ok := p.scopes[table]; ok && !scoped(n.On, table) {
+				n.On = append(slices.Clip(n.On), ScopeCondition{Table: table, Condition: scope})
+			}
+		default:
+			target, where := statementOf(n)
+			if where == nil {
+				break
+			}
+			table, err := resolveTable(target)
+			if err != nil {
+				break
+			}
+			if scope, ok := p.scopes[table]; ok && !scoped(where.Conditions, table) {
+				where.Conditions = append(slices.Clip(where.Conditions), ScopeCondition{Table: table, Condition: scope})
+			}
+		}
+		return true
+	})
+}
+
+// Check whether all statements referencing protected tables are scoped.
+// A statement is scoped only if the ScopeCondition is one of its WHERE conditions, nested ones such as in OR are not counted.
+// So is the ON of JOIN. It fails if any table can not be resolved, see RowPolicy.
+func (p *RowPolicy) Check(root Clause) error {
+	var err error
+	_, _ = Apply(root, func(cursor *Cursor) bool {
+		if err != nil {
+			return false
+		}
+		if err = checkStatement(cursor); err != nil {
+			return false
+		}
+		var (
+			target     Table
+			conditions []Condition
+		)
+		switch n := cursor.Node().(type) {
+		case *Join:
+			target, conditions = n.Table, n.On
+		default:
+			var where *WhereClause
+			if target, where = statementOf(n); where == nil {
+				return true
+			}
+			conditions = where.Conditions
+		}
+		var table string
+		if table, err = resolveTable(target); err != nil {
+			return false
+		}
+		if _, ok := p.scopes[table]; ok && !scoped(conditions, table) {
+			err = fmt.Errorf("table %q is referenced without the row policy", table)
+		}
+		return err == nil
+	}, nil)
+	return err
+}
+
+// Guard the clause, it refuses to parse if the check of the policy fails.
+func (p *RowPolicy) Guard(root Clause) Clause {
+	return NewCustomClause(func(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+		if err := p.Check(root); err != nil {
+			return err
+		}
+		return root.Parse(sqlWriter, argWriter, level)
+	})
+}
+
+// Get the target table and the WHERE clause of the statement, the where is nil if the node is not a statement.
+func statementOf(node Node) (Table, *WhereClause) {
+	switch n := node.(type) {
+	case *DQL:
+		return n.From.Table, &n.Where
+	case *Update:
+		return TableByName(n.Table), &n.Where
+	case *Delete:
+		return TableByName(n.Table), &n.Where
+	default:
+		return Table{}, nil
+	}
+}
+
+// The set operators allowed in Additional, they are matched in upper case.
+var setOperators = map[string]bool{
+	"UNION": true, "UNION ALL": true, "UNION DISTINCT": true,
+	"INTERSECT": true, "INTERSECT ALL": true, "EXCEPT": true, "EXCEPT ALL": true,
+}
+
+// The clause in the position of a statement must be a statement checked by the policy, the others may read protected tables.
+func checkStatement(cursor *Cursor) error {
+	var position bool
+	switch cursor.Parent().(type) {
+	case *WithClause, SubQuery, ExistsCondition:
+		position = true
+	case *Insert:
+		position = cursor.Name() == "Query" || cursor.Name() == "Additional"
+	case *DQL, *Update, *Delete:
+		position = cursor.Name() == "Additional"
+	}
+	if !position {
+		return nil
+	}
+	node := cursor.Node()
+	// The leveled statement such as AddClauseLevel(&DQL{...}, 1) is traversed as well.
+	if c, ok := node.(*addLeveledClause); ok {
+		node = c.clause
+	}
+	if _, where := statementOf(node); where != nil {
+		return nil
+	}
+	if c, ok := node.(*SimpleClause); ok && cursor.Name() == "Additional" && setOperators[strings.ToUpper(strings.Join(strings.Fields(c.Keyword+" "+c.SQL), " "))] {
+		return nil
+	}
+	return NewInvalidError("the row policy can not check %T in %s", cursor.Node(), cursor.Name())
+}
+
+// Get the name of the table, it's empty for no table and DQL sub queries, which are scoped by themselves.
+func resolveTable(table Table) (string, error) {
+	if table.Clause != nil {
+		if _, ok := table.Clause.(*DQL); ok {
+			return "", nil
+		}
+		return "", NewInvalidError("the row policy can not resolve the table of %T", table.Clause)
+	}
+	if table.Name != "" && !plainName(table.Name) {
+		return "", NewInvalidError("the row policy can not resolve the table %q", table.Name)
+	}
+	return table.Name, nil
+}
+
+// Whether the name is an unquoted and unqualified identifier.
+func plainName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i != 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func scoped(conditions []Condition, table string) bool {
+	for _, c := range conditions {
+		if s, ok := c.(ScopeCondition); ok && s.Table == table {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package sqlbuilder_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func newPolicyDQL() *sqlbuilder.DQL {
+	return &sqlbuilder.DQL{
+		With: &sqlbuilder.WithClause{
+			Tables: []sqlbuilder.Table{
+				sqlbuilder.NameAsTable("recent", &sqlbuilder.DQL{
+					From: sqlbuilder.FromTableName("flows"),
+					Where: sqlbuilder.WhereClause{
+						Conditions: []sqlbuilder.Condition{sqlbuilder.NewCondition("ts > ?", 100)},
+					},
+				}),
+			},
+		},
+		From: sqlbuilder.From{sqlbuilder.TableAsName(&sqlbuilder.DQL{From: sqlbuilder.FromTableName("flows")}, "f")},
+		Additional: []sqlbuilder.Clause{
+			sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "UNION ALL"),
+			&sqlbuilder.DQL{From: sqlbuilder.FromTableName("recent")},
+			sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "UNION ALL"),
+			&sqlbuilder.DQL{From: sqlbuilder.FromTableName("hosts")},
+		},
+	}
+}
+
+func TestRowPolicy(t *testing.T) {
+	newPolicy := func() *sqlbuilder.RowPolicy {
+		return sqlbuilder.NewRowPolicy().
+			Protect("flows", sqlbuilder.NewCondition("tenant_id = ?", 7)).
+			Protect("hosts", sqlbuilder.NewCondition("owner = ?", "u"))
+	}
+	t.Run("apply", func(t *testing.T) {
+		RegisterTestingT(t)
+		root, err := newPolicy().Apply(newPolicyDQL())
+		Expect(err).Should(Succeed())
+		sql, args, err := sqlbuilder.Build(root, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("WITH recent AS ( SELECT * FROM flows WHERE ts > ? AND tenant_id = ? ) " +
+			"SELECT * FROM ( SELECT * FROM flows WHERE tenant_id = ? ) AS f " +
+			"UNION ALL SELECT * FROM recent UNION ALL SELECT * FROM hosts WHERE owner = ? "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{100, 7, 7, "u"}))
+		Expect(newPolicy().Check(root)).Should(Succeed())
+	})
+	t.Run("apply twice", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := &sqlbuilder.Delete{Table: "flows"}
+		_, err := newPolicy().Apply(c)
+		Expect(err).Should(Succeed())
+		_, err = newPolicy().Apply(c)
+		Expect(err).Should(Succeed())
+		Expect(c.Where.Conditions).Should(HaveLen(1))
+	})
+	t.Run("dml", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := &sqlbuilder.Update{
+			Table: "hosts",
+			Set:   sqlbuilder.Set{Assignments: []string{"name = ?"}, Args: []sqlbuilder.Arg{"h"}},
+		}
+		root, err := newPolicy().Apply(c)
+		Expect(err).Should(Succeed())
+		sql, args, err := sqlbuilder.Build(root, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("UPDATE hosts SET name = ? WHERE owner = ? "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"h", "u"}))
+	})
+	t.Run("refuse unscoped", func(t *testing.T) {
+		RegisterTestingT(t)
+		policy := newPolicy()
+		c := newPolicyDQL()
+		Expect(policy.Check(c)).Should(MatchError(ContainSubstring(`"flows"`)))
+		err := policy.Guard(c).Parse(bytes.NewBufferString(""), NewArgWriter(0), sqlbuilder.Format)
+		Expect(err).ShouldNot(Succeed())
+	})
+	t.Run("nested scope is not counted", func(t *testing.T) {
+		RegisterTestingT(t)
+		c := &sqlbuilder.Delete{
+			Table: "flows",
+			Where: sqlbuilder.WhereClause{
+				Conditions: []sqlbuilder.Condition{
+					sqlbuilder.Or(
+						sqlbuilder.NewCondition("1 = 1"),
+						sqlbuilder.ScopeCondition{Table: "flows", Condition: sqlbuilder.NewCondition("tenant_id = ?", 7)},
+						sqlbuilder.SaveBrackets,
+					),
+				},
+			},
+		}
+		Expect(newPolicy().Check(c)).ShouldNot(Succeed())
+	})
+	t.Run("join", func(t *testing.T) {
+		RegisterTestingT(t)
+		policy := sqlbuilder.NewRowPolicy().Protect("hosts", sqlbuilder.NewCondition("hosts.owner = ?", "u"))
+		c := sqlbuilder.NewQuery("flows").Join("LEFT JOIN", sqlbuilder.TableByName("hosts"), sqlbuilder.NewCondition("flows.host_id = hosts.id")).DQL()
+		Expect(policy.Check(c)).ShouldNot(Succeed())
+		root, err := policy.Apply(c)
+		Expect(err).Should(Succeed())
+		Expect(policy.Check(root)).Should(Succeed())
+		sql, args, err := sqlbuilder.Build(root, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT * FROM flows LEFT JOIN hosts ON flows.host_id = hosts.id AND hosts.owner = ? "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{"u"}))
+		Expect(sqlbuilder.ReferencedTables(root)).Should(Equal([]string{"flows", "hosts"}))
+	})
+	t.Run("fail closed", func(t *testing.T) {
+		RegisterTestingT(t)
+		tables := []sqlbuilder.Table{
+			sqlbuilder.TableByName("flows AS f"),
+			sqlbuilder.TableByName("public.flows"),
+			sqlbuilder.TableByName(`"flows"`),
+			sqlbuilder.TableByClause(sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "flows")),
+		}
+		for _, table := range tables {
+			c := &sqlbuilder.DQL{From: sqlbuilder.From{Table: table}}
+			root, err := newPolicy().Apply(c)
+			Expect(err).Should(Succeed())
+			Expect(newPolicy().Check(root)).Should(MatchError(sqlbuilder.ErrInvalidStructure))
+			j := sqlbuilder.NewQuery("devices").Join("JOIN", table).DQL()
+			Expect(newPolicy().Check(j)).Should(MatchError(ContainSubstring("can not resolve")))
+		}
+		Expect(newPolicy().Check(&sqlbuilder.Delete{Table: "public.flows"})).ShouldNot(Succeed())
+		Expect(newPolicy().Check(&sqlbuilder.DQL{Select: sqlbuilder.Select{Columns: []string{"1"}}})).Should(Succeed())
+	})
+	t.Run("fail closed on opaque statements", func(t *testing.T) {
+		RegisterTestingT(t)
+		read := sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "SELECT * FROM flows")
+		roots := []sqlbuilder.Clause{
+			&sqlbuilder.DQL{
+				With: &sqlbuilder.WithClause{Tables: []sqlbuilder.Table{sqlbuilder.NameAsTable("leak", read)}},
+				From: sqlbuilder.FromTableName("leak"),
+			},
+			&sqlbuilder.DQL{
+				From:       sqlbuilder.FromTableName("devices"),
+				Additional: []sqlbuilder.Clause{sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "UNION SELECT * FROM flows")},
+			},
+			&sqlbuilder.Insert{Table: "devices", Query: read},
+			&sqlbuilder.DQL{
+				From:  sqlbuilder.FromTableName("devices"),
+				Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.Exists(read)}},
+			},
+			&sqlbuilder.DQL{Select: sqlbuilder.Select{Expressions: []sqlbuilder.Expression{sqlbuilder.NewSubQuery(read)}}},
+		}
+		for _, root := range roots {
+			root, err := newPolicy().Apply(root)
+			Expect(err).Should(Succeed())
+			Expect(newPolicy().Check(root)).Should(MatchError(ContainSubstring("can not check")))
+		}
+
+		// The statements in those positions are checked.
+		l := &sqlbuilder.DQL{
+			From: sqlbuilder.FromTableName("devices"),
+			Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{
+				sqlbuilder.Exists(&sqlbuilder.DQL{From: sqlbuilder.FromTableName("flows")}),
+			}},
+			Additional: []sqlbuilder.Clause{
+				sqlbuilder.NewKeywordClause(sqlbuilder.AutoNewline, "union", "all"),
+				sqlbuilder.AddClauseLevel(&sqlbuilder.DQL{From: sqlbuilder.FromTableName("hosts")}, 1),
+			},
+		}
+		Expect(newPolicy().Check(l)).Should(MatchError(`table "flows" is referenced without the row policy`))
+		root, err := newPolicy().Apply(l)
+		Expect(err).Should(Succeed())
+		Expect(newPolicy().Check(root)).Should(Succeed())
+	})
+	t.Run("shared conditions", func(t *testing.T) {
+		RegisterTestingT(t)
+		shared := make([]sqlbuilder.Condition, 1, 2)
+		shared[0] = sqlbuilder.NewCondition("id = ?", 1)
+		a := &sqlbuilder.Delete{Table: "flows", Where: sqlbuilder.WhereClause{Conditions: shared}}
+		b := &sqlbuilder.Delete{Table: "hosts", Where: sqlbuilder.WhereClause{Conditions: shared}}
+		_, err := newPolicy().Apply(a)
+		Expect(err).Should(Succeed())
+		_, err = newPolicy().Apply(b)
+		Expect(err).Should(Succeed())
+		Expect(a.Where.Conditions[1]).Should(Equal(sqlbuilder.ScopeCondition{Table: "flows", Condition: sqlbuilder.NewCondition("tenant_id = ?", 7)}))
+		Expect(b.Where.Conditions[1]).Should(Equal(sqlbuilder.ScopeCondition{Table: "hosts", Condition: sqlbuilder.NewCondition("owner = ?", "u")}))
+	})
+	t.Run("guard scoped", func(t *testing.T) {
+		RegisterTestingT(t)
+		policy := newPolicy()
+		root, err := policy.Apply(&sqlbuilder.Delete{Table: "flows"})
+		Expect(err).Should(Succeed())
+		buff := bytes.NewBufferString("")
+		var argWriter = NewArgWriter(0)
+		err = policy.Guard(root).Parse(buff, argWriter, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		Expect(buff.String()).Should(Equal("DELETE FROM flows\nWHERE\n  tenant_id = ?\n"))
+		Expect(argWriter.Args).Should(Equal([]sqlbuilder.Arg{7}))
+	})
+}
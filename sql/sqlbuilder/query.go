@@ -0,0 +1,159 @@
+package sqlbuilder
+
+import (
+	"io"
+)
+
+/*
+Query is an immutable builder of DQL, such as q.Where(...).OrderBy(...).Limit(...).
+Every method returns a new Query holding a clone of the DQL, the receiver is never modified.
+So that a base query can be shared by goroutines safely.
+The tables, conditions, expressions and clauses passed to the methods are cloned as well, see CloneClause for the limits.
+*/
+type Query struct {
+	dql *DQL
+}
+
+// Create a Query from a table name.
+func NewQuery(table string) Query {
+	return Query{dql: &DQL{From: FromTableName(table)}}
+}
+
+// Create a Query from a DQL, the DQL is cloned and can be modified later.
+func QueryOf(l *DQL) Query {
+	return Query{dql: l.Clone()}
+}
+
+func (q Query) clone() Query {
+	if q.dql == nil {
+		return Query{dql: &DQL{}}
+	}
+	return Query{dql: q.dql.Clone()}
+}
+
+// Get a clone of the DQL.
+func (q Query) DQL() *DQL {
+	return q.clone().dql
+}
+
+func (q Query) Parse(sqlWriter io.StringWriter, argWriter ArgWriter, level int) error {
+	if q.dql == nil {
+		return (&DQL{}).Parse(sqlWriter, argWriter, level)
+	}
+	return q.dql.Parse(sqlWriter, argWriter, level)
+}
+
+// Append the tables to the WITH clause, the With of DQL is replaced if it is not a *WithClause.
+// The tables are cloned, so that the Query is not affected by modifying them later.
+func (q Query) With(tables ...Table) Query {
+	n := q.clone()
+	with, ok := n.dql.With.(*WithClause)
+	if !ok || with == nil {
+		with = &WithClause{}
+		n.dql.With = with
+	}
+	for _, table := range tables {
+		with.Tables = append(with.Tables, table.Clone())
+	}
+	return n
+}
+
+// Append the columns to the SELECT clause.
+func (q Query) Select(columns ...string) Query {
+	n := q.clone()
+	n.dql.Select.Columns = append(n.dql.Select.Columns, columns...)
+	return n
+}
+
+// Append a column with arguments to the SELECT clause, such as SelectArgs("max(y+?) AS max_y", 1).
+func (q Query) SelectArgs(column string, args ...Arg) Query {
+	n := q.clone()
+	n.dql.Select.Columns = append(n.dql.Select.Columns, column)
+	n.dql.Select.Args = append(n.dql.Select.Args, args...)
+	return n
+}
+
+// Append the expressions to the SELECT clause, they are selected after the columns. The expressions are cloned.
+func (q Query) SelectExpr(exprs ...Expression) Query {
+	n := q.clone()
+	n.dql.Select.Expressions = append(n.dql.Select.Expressions, cloneExpressions(exprs)...)
+	return n
+}
+
+func (q Query) From(table Table) Query {
+	n := q.clone()
+	n.dql.From = From{Table: table.Clone()}
+	return n
+}
+
+// Append a JOIN clause, such as Join("LEFT JOIN", TableByName("b"), NewCondition("a.id = b.a_id")).
+// The table and conditions are cloned.
+func (q Query) Join(kind string, table Table, on ...Condition) Query {
+	n := q.clone()
+	n.dql.Joins = append(n.dql.Joins, Join{Kind: kind, Table: table.Clone(), On: cloneConditions(on)})
+	return n
+}
+
+// Append the conditions to the WHERE clause. The conditions are cloned.
+func (q Query) Where(conditions ...Condition) Query {
+	n := q.clone()
+	n.dql.Where.Conditions = append(n.dql.Where.Conditions, cloneConditions(conditions)...)
+	return n
+}
+
+func (q Query) GroupBy(value string, args ...Arg) Query {
+	n := q.clone()
+	n.dql.Group = MakeGroupby(value, args...)
+	return n
+}
+
+// Replace the GROUP BY clause with the expressions. The expressions are cloned.
+func (q Query) GroupByExpr(exprs ...Expression) Query {
+	n := q.clone()
+	n.dql.Group = GroupBy(cloneExpressions(exprs)...)
+	return n
+}
+
+// Append the conditions to the HAVING clause. The conditions are cloned.
+func (q Query) Having(conditions ...Condition) Query {
+	n := q.clone()
+	n.dql.Having.Conditions = append(n.dql.Having.Conditions, cloneConditions(conditions)...)
+	return n
+}
+
+func (q Query) OrderBy(value string, args ...Arg) Query {
+	n := q.clone()
+	n.dql.Order = MakeOrderby(value, args...)
+	return n
+}
+
+// Replace the ORDER BY clause with the terms. The expressions of terms are cloned.
+func (q Query) OrderByTerms(terms ...OrderTerm) Query {
+	n := q.clone()
+	cloned := make([]OrderTerm, len(terms))
+	for i, t := range terms {
+		cloned[i] = t.Clone()
+	}
+	n.dql.Order = OrderBy(cloned...)
+	return n
+}
+
+func (q Query) Limit(value string, args ...Arg) Query {
+	n := q.clone()
+	n.dql.Limit = MakeLimit(value, args...)
+	return n
+}
+
+// Replace the locking clause, such as Lock(Lock{Strength: ForUpdate, Wait: SkipLocked}).
+func (q Query) Lock(lock Lock) Query {
+	n := q.clone()
+	n.dql.Lock = lock.Clone()
+	return n
+}
+
+// Append the clauses to the end of DQL. The clauses are cloned.
+func (q Query) Additional(clauses ...Clause) Query {
+	n := q.clone()
+	n.dql.Additional = append(n.dql.Additional, Clauses(clauses).Clone()...)
+	return n
+}
@@ -0,0 +1,112 @@
+package sqlbuilder_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestQuery(t *testing.T) {
+	t.Run("build", func(t *testing.T) {
+		RegisterTestingT(t)
+		q := sqlbuilder.NewQuery("demo_table").
+			With(sqlbuilder.NameAsTable("a", sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "SELECT * FROM demo.A"))).
+			Select("x").
+			SelectArgs("max(y+?) AS max_y", 1).
+			Where(sqlbuilder.NewCondition("x >= ?", 2)).
+			GroupBy("x").
+			Having(sqlbuilder.NewCondition("max_y < ?", 3)).
+			OrderBy("x").
+			Limit("?", 4).
+			Additional(sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "OFFSET ?", 5))
+		sql, args, err := sqlbuilder.Build(q, sqlbuilder.Generic, sqlbuilder.Format)
+		Expect(err).Should(Succeed())
+		ept := "WITH\na AS (\n  SELECT * FROM demo.A\n)\nSELECT\n  x,\n  max(y+?) AS max_y\nFROM demo_table\nWHERE\n  x >= ?\n" +
+			"GROUP BY x\nHAVING\n  max_y < ?\nORDER BY x\nLIMIT ?\nOFFSET ?\n"
+		Expect(sql).Should(Equal(ept))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{1, 2, 3, 4, 5}))
+	})
+	t.Run("immutable", func(t *testing.T) {
+		RegisterTestingT(t)
+		base := sqlbuilder.NewQuery("demo_table").Where(sqlbuilder.NewCondition("x = ?", 1))
+		q1 := base.Where(sqlbuilder.NewCondition("y = ?", 2))
+		q2 := base.Where(sqlbuilder.NewCondition("z = ?", 3)).Limit("1")
+		sql, _, err := sqlbuilder.Build(base, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT * FROM demo_table WHERE x = ? "))
+		sql, _, err = sqlbuilder.Build(q1, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT * FROM demo_table WHERE x = ? AND y = ? "))
+		sql, _, err = sqlbuilder.Build(q2, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT * FROM demo_table WHERE x = ? AND z = ? LIMIT 1 "))
+	})
+	t.Run("query of dql", func(t *testing.T) {
+		RegisterTestingT(t)
+		l := &sqlbuilder.DQL{From: sqlbuilder.FromTableName("demo_table")}
+		q := sqlbuilder.QueryOf(l).Where(sqlbuilder.NewCondition("x"))
+		l.From = sqlbuilder.FromTableName("other_table")
+		Expect(q.DQL().From).Should(Equal(sqlbuilder.FromTableName("demo_table")))
+		Expect(l.Where.Valid()).Should(BeFalse())
+		var empty sqlbuilder.Query
+		sql, _, err := sqlbuilder.Build(empty.From(sqlbuilder.TableByName("t")), sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT * FROM t "))
+	})
+	t.Run("with cloned tables", func(t *testing.T) {
+		RegisterTestingT(t)
+		tables := []sqlbuilder.Table{sqlbuilder.NameAsTable("a", &sqlbuilder.DQL{From: sqlbuilder.FromTableName("b")})}
+		q := sqlbuilder.NewQuery("a").With(tables...)
+		tables[0].Name = "c"
+		tables[0].Clause.(*sqlbuilder.DQL).From = sqlbuilder.FromTableName("d")
+		sql, _, err := sqlbuilder.Build(q, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("WITH a AS ( SELECT * FROM b ) SELECT * FROM a "))
+	})
+	t.Run("join cloned", func(t *testing.T) {
+		RegisterTestingT(t)
+		on := []sqlbuilder.Condition{sqlbuilder.NewCondition("a.id = b.id")}
+		q := sqlbuilder.NewQuery("a").Join("JOIN", sqlbuilder.TableByName("b"), on...)
+		on[0] = sqlbuilder.NewCondition("a.id = b.a_id")
+		sql, _, err := sqlbuilder.Build(q, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT * FROM a JOIN b ON a.id = b.id "))
+	})
+	t.Run("arguments cloned", func(t *testing.T) {
+		RegisterTestingT(t)
+		sub := &sqlbuilder.DQL{From: sqlbuilder.FromTableName("b")}
+		args := []sqlbuilder.Arg{1}
+		q := sqlbuilder.NewQuery("a").
+			Where(sqlbuilder.Exists(sub), sqlbuilder.NewCondition("x = ?", args...)).
+			Having(sqlbuilder.NewCondition("y = ?", args...)).
+			Additional(sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "UNION ALL"), sub)
+		sub.From = sqlbuilder.FromTableName("c")
+		args[0] = 2
+		sql, values, err := sqlbuilder.Build(q, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT * FROM a WHERE EXISTS ( SELECT * FROM b ) AND x = ? HAVING y = ? UNION ALL SELECT * FROM b "))
+		Expect(values).Should(Equal([]sqlbuilder.Arg{1, 1}))
+	})
+	t.Run("concurrency", func(t *testing.T) {
+		RegisterTestingT(t)
+		base := sqlbuilder.NewQuery("demo_table").Where(sqlbuilder.NewCondition("x = ?", 0))
+		var wg sync.WaitGroup
+		results := make([]string, 16)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				q := base.Where(sqlbuilder.NewCondition(fmt.Sprintf("c%d = ?", i), i))
+				results[i], _, _ = sqlbuilder.Build(q, sqlbuilder.Generic, sqlbuilder.Compact)
+			}(i)
+		}
+		wg.Wait()
+		for i, res := range results {
+			Expect(res).Should(Equal(fmt.Sprintf("SELECT * FROM demo_table WHERE x = ? AND c%d = ? ", i)))
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package sqlbuilder
+
+import (
+	"sync/atomic"
+
+	"github.com/everoute/util/pool"
+)
+
+// Buffer is a StyledWriter and DialectArgWriter which never fails, it's reused by Renderer through a pool.
+type Buffer struct {
+	sql     []byte
+	args    []Arg
+	dialect Dialect
+	style   *Style
+}
+
+func (b *Buffer) WriteString(s string) (int, error) {
+	b.sql = append(b.sql, s...)
+	return len(s), nil
+}
+
+func (b *Buffer) WriteArg(arg Arg) error {
+	b.args = append(b.args, arg)
+	return nil
+}
+
+func (b *Buffer) Dialect() Dialect {
+	return b.dialect
+}
+
+// Get the style of the buffer, nil means DefaultStyle.
+func (b *Buffer) Style() *Style {
+	return b.style
+}
+
+func (b *Buffer) Len() int {
+	return len(b.args)
+}
+
+// Get the SQL written, it's copied.
+func (b *Buffer) String() string {
+	return string(b.sql)
+}
+
+// Get the arguments written, they are invalid after the buffer is reset.
+func (b *Buffer) Args() []Arg {
+	return b.args
+}
+
+// Reset the buffer for the dialect, the allocated memory is kept and grown to the size hints.
+func (b *Buffer) Reset(dialect Dialect, sqlHint, argsHint int) {
+	if cap(b.sql) < sqlHint {
+		b.sql = make([]byte, 0, sqlHint)
+	}
+	if cap(b.args) < argsHint {
+		b.args = make([]Arg, 0, argsHint)
+	}
+	b.sql = b.sql[:0]
+	clear(b.args)
+	b.args = b.args[:0]
+	b.dialect = dialect
+}
+
+const (
+	// The buffers larger than it are dropped instead of being put back into the pool.
+	maxPooledSQL  = 64 << 10
+	maxPooledArgs = 4 << 10
+)
+
+var bufferPool = pool.NewStdPoll[Buffer](nil)
+
+/*
+Renderer renders the clauses such as hot queries with pooled buffers,
+the output is identical to Build, but the buffers of SQL and arguments are reused instead of growing for each clause.
+The arguments are still boxed into Arg, which is any, by the clauses writing them.
+It remembers the largest sizes rendered as hints to preallocate the buffers, so use a Renderer for similar clauses.
+A Renderer is safe for concurrent use.
+*/
+type Renderer struct {
+	Dialect Dialect
+	Level   int
+	// The style of the SQL, DefaultStyle is used if it's nil.
+	Style    *Style
+	sqlHint  atomic.Int64
+	argsHint atomic.Int64
+}
+
+func NewRenderer(dialect Dialect, level int) *Renderer {
+	return &Renderer{
+		Dialect: dialect,
+		Level:   level,
+	}
+}
+
+// Render the clause into a pooled buffer, fn must not retain the buffer.
+func (r *Renderer) Render(c Clause, fn func(b *Buffer) error) error {
+	b := bufferPool.Get()
+	b.Reset(r.Dialect, int(r.sqlHint.Load()), int(r.argsHint.Load()))
+	b.style = r.Style
+	defer func() {
+		if cap(b.sql) <= maxPooledSQL && cap(b.args) <= maxPooledArgs {
+			b.Reset(Generic, 0, 0)
+			b.style = nil
+			bufferPool.Put(b)
+		}
+	}()
+	if err := c.Parse(b, b, r.Level); err != nil {
+		return locateRoot(err, c)
+	}
+	updateHint(&r.sqlHint, min(len(b.sql), maxPooledSQL))
+	updateHint(&r.argsHint, min(len(b.args), maxPooledArgs))
+	return fn(b)
+}
+
+// Build the clause, returns the SQL and its arguments.
+func (r *Renderer) Build(c Clause) (string, []Arg, error) {
+	var (
+		sql  string
+		args []Arg
+	)
+	err := r.Render(c, func(b *Buffer) error {
+		sql = b.String()
+		args = append(make([]Arg, 0, len(b.args)), b.args...)
+		return nil
+	})
+	return sql, args, err
+}
+
+// Build the clause, the arguments can be passed to database/sql directly.
+func (r *Renderer) BuildAny(c Clause) (string, []any, error) {
+	var (
+		sql  string
+		args []any
+	)
+	err := r.Render(c, func(b *Buffer) error {
+		sql = b.String()
+		args = make([]any, len(b.args))
+		for i, arg := range b.args {
+			args[i] = arg
+		}
+		return nil
+	})
+	return sql, args, err
+}
+
+func updateHint(hint *atomic.Int64, size int) {
+	for {
+		old := hint.Load()
+		if int64(size) <= old || hint.CompareAndSwap(old, int64(size)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package sqlbuilder_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func hotQuery() *sqlbuilder.DQL {
+	return &sqlbuilder.DQL{
+		With: &sqlbuilder.WithClause{Tables: []sqlbuilder.Table{
+			sqlbuilder.NameAsTable("recent", sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "SELECT * FROM flows WHERE ts > ?", 100)),
+		}},
+		Select: sqlbuilder.Select{Columns: []string{"recent.id", "recent.name", "labels.value"}},
+		From:   sqlbuilder.FromTableName("recent"),
+		Joins: []sqlbuilder.Join{{
+			Kind:  "LEFT JOIN",
+			Table: sqlbuilder.TableByName("labels"),
+			On:    []sqlbuilder.Condition{sqlbuilder.SimpleCondition{Str: "labels.flow_id = recent.id"}},
+		}},
+		Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{
+			sqlbuilder.NewColumnCondition("recent.name", "IN", "a", "b", "c"),
+			sqlbuilder.Or(sqlbuilder.NewColumnCondition("labels.key", "=", "app"), sqlbuilder.NewColumnCondition("labels.key", "IS NULL"), sqlbuilder.SaveBrackets),
+		}},
+		Order: sqlbuilder.MakeOrderby("recent.id DESC"),
+		Limit: sqlbuilder.MakeLimit("100"),
+	}
+}
+
+func TestRenderer(t *testing.T) {
+	t.Run("identical", func(t *testing.T) {
+		RegisterTestingT(t)
+		clauses := []sqlbuilder.Clause{
+			hotQuery(),
+			&sqlbuilder.Select{},
+			&sqlbuilder.Insert{Table: "t", Columns: []string{"a"}, Values: [][]sqlbuilder.Arg{{1}, {2}}},
+		}
+		for _, dialect := range []sqlbuilder.Dialect{sqlbuilder.Generic, sqlbuilder.PostgreSQL} {
+			for _, level := range []int{sqlbuilder.Format, sqlbuilder.Compact, 2} {
+				r := sqlbuilder.NewRenderer(dialect, level)
+				for _, c := range clauses {
+					expectedSQL, expectedArgs, err := sqlbuilder.Build(c, dialect, level)
+					Expect(err).Should(Succeed())
+					for i := 0; i < 2; i++ {
+						sql, args, err := r.Build(c)
+						Expect(err).Should(Succeed())
+						Expect(sql).Should(Equal(expectedSQL))
+						Expect(args).Should(Equal(expectedArgs))
+					}
+					sql, args, err := r.BuildAny(c)
+					Expect(err).Should(Succeed())
+					Expect(sql).Should(Equal(expectedSQL))
+					Expect(args).Should(HaveLen(len(expectedArgs)))
+				}
+			}
+		}
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		RegisterTestingT(t)
+		r := sqlbuilder.NewRenderer(sqlbuilder.PostgreSQL, sqlbuilder.Compact)
+		expected, _, err := sqlbuilder.Build(hotQuery(), sqlbuilder.PostgreSQL, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		var wg sync.WaitGroup
+		results := make([]string, 16)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					results[i], _, _ = r.Build(hotQuery())
+				}
+			}(i)
+		}
+		wg.Wait()
+		for _, sql := range results {
+			Expect(sql).Should(Equal(expected))
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, _, err := sqlbuilder.NewRenderer(sqlbuilder.SQLite, sqlbuilder.Compact).Build(sqlbuilder.Lock{Strength: sqlbuilder.ForUpdate})
+		Expect(err).Should(MatchError("Lock: FOR UPDATE is not supported by sqlite"))
+	})
+}
+
+func BenchmarkDQLParse(b *testing.B) {
+	l := hotQuery()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var builder strings.Builder
+		args := sqlbuilder.NewArgList(sqlbuilder.PostgreSQL, 0)
+		if err := l.Parse(&builder, args, sqlbuilder.Compact); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuild(b *testing.B) {
+	l := hotQuery()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := sqlbuilder.Build(l, sqlbuilder.PostgreSQL, sqlbuilder.Compact); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRenderer(b *testing.B) {
+	l := hotQuery()
+	r := sqlbuilder.NewRenderer(sqlbuilder.PostgreSQL, sqlbuilder.Compact)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := r.Build(l); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package sqlbuildertest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The number of unchanged lines around the changes in the unified diff.
+const diffContext = 3
+
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// Diff returns the unified diff from a to b by lines, it's empty if they are equal.
+func Diff(aName, bName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	lines := diffLines(splitLines(a), splitLines(b))
+	var res strings.Builder
+	fmt.Fprintf(&res, "--- %s\n+++ %s\n", aName, bName)
+	// The index of lines, and the line numbers in a and b at the index.
+	aLine, bLine := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	for i, l := range lines {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if l.op != '+' {
+			aLine[i+1]++
+		}
+		if l.op != '-' {
+			bLine[i+1]++
+		}
+	}
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			i++
+			continue
+		}
+		// Extend the hunk until there are enough unchanged lines after the changes.
+		start, end := max(i-diffContext, 0), i
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next == len(lines) || next-end > 2*diffContext {
+				end = min(end+diffContext, len(lines))
+				break
+			}
+			end = next
+		}
+		fmt.Fprintf(&res, "@@ -%s +%s @@\n", hunkRange(aLine[start], aLine[end]), hunkRange(bLine[start], bLine[end]))
+		for _, l := range lines[start:end] {
+			res.WriteByte(l.op)
+			res.WriteString(l.text)
+			res.WriteByte('\n')
+		}
+		i = end
+	}
+	return res.String()
+}
+
+func hunkRange(begin, end int) string {
+	if end-begin == 1 {
+		return fmt.Sprint(begin + 1)
+	}
+	if end == begin {
+		return fmt.Sprintf("%d,0", begin)
+	}
+	return fmt.Sprintf("%d,%d", begin+1, end-begin)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Diff the lines by the longest common subsequence, the golden files are small enough.
+func diffLines(a, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	res := make([]diffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			res = append(res, diffLine{op: ' ', text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			res = append(res, diffLine{op: '-', text: a[i]})
+			i++
+		default:
+			res = append(res, diffLine{op: '+', text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		res = append(res, diffLine{op: '-', text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		res = append(res, diffLine{op: '+', text: b[j]})
+	}
+	return res
+}
@@ -0,0 +1,37 @@
+package sqlbuildertest_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder/sqlbuildertest"
+)
+
+func TestDiff(t *testing.T) {
+	t.Run("equal", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(sqlbuildertest.Diff("a", "b", "x\ny\n", "x\ny\n")).Should(BeEmpty())
+	})
+	t.Run("hunks", func(t *testing.T) {
+		RegisterTestingT(t)
+		var a, b []string
+		for i := 0; i < 20; i++ {
+			a = append(a, string(rune('a'+i)))
+		}
+		b = append(b, a...)
+		b[1] = "B"
+		b[2] = "C"
+		b = append(b[:15], append([]string{"new"}, b[15:]...)...)
+		Expect(sqlbuildertest.Diff("want", "got", strings.Join(a, "\n")+"\n", strings.Join(b, "\n")+"\n")).Should(Equal(
+			"--- want\n+++ got\n" +
+				"@@ -1,6 +1,6 @@\n a\n-b\n-c\n+B\n+C\n d\n e\n f\n" +
+				"@@ -13,6 +13,7 @@\n m\n n\n o\n+new\n p\n q\n r\n",
+		))
+	})
+	t.Run("empty", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(sqlbuildertest.Diff("want", "got", "", "a\n")).Should(Equal("--- want\n+++ got\n@@ -0,0 +1 @@\n+a\n"))
+	})
+}
@@ -0,0 +1,110 @@
+/*
+Package sqlbuildertest compares the rendered clauses with golden files in tests.
+
+A golden file contains the SQL in both Format and Compact modes and the arguments, such as:
+
+	-- format
+	SELECT *
+	FROM flows
+	-- compact
+	SELECT * FROM flows
+	-- args
+	1: int 1
+
+Run the tests with -update to regenerate the golden files, such as "go test ./sql/sqlbuilder/sqlbuildertest -update",
+the flag is defined only in the test binaries importing this package.
+*/
+package sqlbuildertest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+var update = flag.Bool("update", false, "update the golden files of sqlbuildertest")
+
+// The directory of golden files, it's relative to the package under test.
+var Dir = "testdata"
+
+// Render the clause in both Format and Compact modes, the result is the content of golden file.
+// The trailing spaces of the Compact SQL are trimmed, so that the golden files are not changed by editors.
+func Render(c sqlbuilder.Clause, dialect sqlbuilder.Dialect) (string, error) {
+	format, args, err := sqlbuilder.Build(c, dialect, sqlbuilder.Format)
+	if err != nil {
+		return "", fmt.Errorf("render in format mode: %w", err)
+	}
+	compact, compactArgs, err := sqlbuilder.Build(c, dialect, sqlbuilder.Compact)
+	if err != nil {
+		return "", fmt.Errorf("render in compact mode: %w", err)
+	}
+	if !reflect.DeepEqual(args, compactArgs) {
+		return "", errors.New("the arguments are different between format and compact modes")
+	}
+	var b strings.Builder
+	b.WriteString("-- format\n")
+	b.WriteString(format)
+	if !strings.HasSuffix(format, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("-- compact\n")
+	b.WriteString(strings.TrimRight(compact, " "))
+	b.WriteString("\n-- args\n")
+	for i, arg := range args {
+		fmt.Fprintf(&b, "%d: %T %#v\n", i+1, arg, arg)
+	}
+	return b.String(), nil
+}
+
+/*
+Golden renders the clause for the dialect and compares it with the golden file Dir/name.golden,
+the name can contain slashes to group the golden files, such as "dql/join".
+The differences are reported by a unified diff, and the golden file is written instead if -update is set.
+*/
+func Golden(t testing.TB, name string, c sqlbuilder.Clause, dialect sqlbuilder.Dialect) {
+	t.Helper()
+	got, err := Render(c, dialect)
+	if err != nil {
+		t.Fatalf("render %s: %s", name, err)
+		return
+	}
+	path := filepath.Join(Dir, filepath.FromSlash(name)+".golden")
+	if *update {
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create directory of %s: %s", path, err)
+			return
+		}
+		if err = os.WriteFile(path, []byte(got), 0o600); err != nil {
+			t.Fatalf("update %s: %s", path, err)
+			return
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("golden file %s not found, run the test with -update to create it", path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("read %s: %s", path, err)
+		return
+	}
+	if string(want) != got {
+		t.Errorf("%s mismatch, run the test with -update to accept the changes:\n%s", path, Diff(path, "got", string(want), got))
+	}
+}
+
+// Compare the clause with the golden files for each dialect, the dialect is appended to the name, such as "dql/join.postgresql".
+func GoldenDialects(t testing.TB, name string, c sqlbuilder.Clause, dialects ...sqlbuilder.Dialect) {
+	t.Helper()
+	for _, dialect := range dialects {
+		Golden(t, name+"."+dialect.String(), c, dialect)
+	}
+}
@@ -0,0 +1,69 @@
+package sqlbuildertest_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/sqlbuildertest"
+)
+
+// recorder records the failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+	r.fatal = true
+}
+
+func query() sqlbuilder.Clause {
+	return sqlbuilder.NewQuery("flows").
+		With(sqlbuilder.NameAsTable("recent", sqlbuilder.NewQuery("flows").Where(sqlbuilder.NewColumnCondition("time", ">", 100)))).
+		Join("JOIN", sqlbuilder.TableByName("recent"), sqlbuilder.NewCondition("recent.id = flows.id")).
+		Where(sqlbuilder.NewColumnCondition("src", "IN", "a", "b")).
+		OrderBy("flows.id").
+		Limit("10")
+}
+
+func TestGolden(t *testing.T) {
+	t.Run("match", func(t *testing.T) {
+		sqlbuildertest.GoldenDialects(t, "dql/join", query(), sqlbuilder.Generic, sqlbuilder.PostgreSQL)
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		RegisterTestingT(t)
+		r := &recorder{TB: t}
+		sqlbuildertest.Golden(r, "dql/join.generic", sqlbuilder.NewQuery("flows").Limit("10"), sqlbuilder.Generic)
+		Expect(r.fatal).Should(BeFalse())
+		Expect(r.errors).Should(HaveLen(1))
+		Expect(r.errors[0]).Should(ContainSubstring("testdata/dql/join.generic.golden mismatch"))
+		Expect(r.errors[0]).Should(ContainSubstring("--- testdata/dql/join.generic.golden\n+++ got\n@@ -1,21 +1,7 @@\n -- format\n-WITH\n"))
+	})
+	t.Run("not found", func(t *testing.T) {
+		RegisterTestingT(t)
+		r := &recorder{TB: t}
+		sqlbuildertest.Golden(r, "missing", query(), sqlbuilder.Generic)
+		Expect(r.fatal).Should(BeTrue())
+		Expect(r.errors).Should(ConsistOf(ContainSubstring("run the test with -update to create it")))
+	})
+	t.Run("render", func(t *testing.T) {
+		RegisterTestingT(t)
+		res, err := sqlbuildertest.Render(sqlbuilder.NewQuery("flows").Where(sqlbuilder.NewCondition("id = ?", 1)), sqlbuilder.MySQL)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(res).Should(Equal("-- format\nSELECT *\nFROM flows\nWHERE\n  id = ?\n-- compact\nSELECT * FROM flows WHERE id = ?\n-- args\n1: int 1\n"))
+
+		_, err = sqlbuildertest.Render(&sqlbuilder.Insert{Table: "flows"}, sqlbuilder.MySQL)
+		Expect(err).Should(MatchError(sqlbuilder.ErrInvalidStructure))
+	})
+}
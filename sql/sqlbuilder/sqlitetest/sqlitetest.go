@@ -0,0 +1,163 @@
+/*
+Package sqlitetest executes the clauses against an in-memory SQLite database in tests,
+so that the semantics of queries can be asserted without an external database.
+
+	db := sqlitetest.Open(t, &sqlbuilder.CreateTable{Name: "flows", Columns: columns})
+	db.MustInsert("flows", []string{"id", "src"}, []sqlbuilder.Arg{1, "a"})
+	res := db.MustQuery(sqlbuilder.NewQuery("flows").Select("src"))
+*/
+package sqlitetest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+// The clauses are rendered in compact mode, so the SQL in error messages is a single line.
+var renderer = sqlbuilder.NewRenderer(sqlbuilder.SQLite, sqlbuilder.Compact)
+
+// DB is an in-memory SQLite database which is closed when the test finishes.
+type DB struct {
+	*sql.DB
+	t testing.TB
+}
+
+/*
+Open an in-memory SQLite database and create the schema, such as *sqlbuilder.CreateTable,
+*sqlbuilder.CreateIndex, or raw statements by sqlbuilder.NewSimpleClause.
+The test fails immediately if the schema can not be created.
+*/
+func Open(t testing.TB, schema ...sqlbuilder.Clause) *DB {
+	t.Helper()
+	sqlDB, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %s", err)
+		return nil
+	}
+	// Each connection has its own in-memory database, so only one connection is used.
+	// Read the rows before executing another statement, or it will be blocked.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		_ = sqlDB.Close()
+	})
+	db := &DB{DB: sqlDB, t: t}
+	for _, c := range schema {
+		db.MustExec(c)
+	}
+	return db
+}
+
+// Result is the rows returned by a query, the values are the types returned by the driver,
+// such as int64, float64, string, []byte and nil.
+type Result struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// Get the values of the column in each row, it's nil if the column is not found.
+func (r *Result) Column(name string) []any {
+	for i, column := range r.Columns {
+		if column == name {
+			res := make([]any, len(r.Rows))
+			for j, row := range r.Rows {
+				res[j] = row[i]
+			}
+			return res
+		}
+	}
+	return nil
+}
+
+// Get each row as a map from the column to the value.
+func (r *Result) Maps() []map[string]any {
+	res := make([]map[string]any, len(r.Rows))
+	for i, row := range r.Rows {
+		res[i] = make(map[string]any, len(r.Columns))
+		for j, column := range r.Columns {
+			res[i][column] = row[j]
+		}
+	}
+	return res
+}
+
+// Render the clause with the SQLite dialect.
+func Render(c sqlbuilder.Clause) (string, []any, error) {
+	return renderer.BuildAny(c)
+}
+
+// Execute the clause such as DML and DDL.
+func (db *DB) Exec(c sqlbuilder.Clause) (sql.Result, error) {
+	query, args, err := Render(c)
+	if err != nil {
+		return nil, err
+	}
+	res, err := db.ExecContext(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("exec %q: %w", query, err)
+	}
+	return res, nil
+}
+
+// Execute the clause, the test fails immediately if there is an error.
+func (db *DB) MustExec(c sqlbuilder.Clause) sql.Result {
+	db.t.Helper()
+	res, err := db.Exec(c)
+	if err != nil {
+		db.t.Fatalf("%s", err)
+	}
+	return res
+}
+
+// Query the clause such as DQL, or DML with RETURNING.
+func (db *DB) Query(c sqlbuilder.Clause) (*Result, error) {
+	query, args, err := Render(c)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query %q: %w", query, err)
+	}
+	defer rows.Close()
+	res := &Result{}
+	if res.Columns, err = rows.Columns(); err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		row := make([]any, len(res.Columns))
+		dest := make([]any, len(row))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan the rows of %q: %w", query, err)
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("query %q: %w", query, err)
+	}
+	return res, nil
+}
+
+// Query the clause, the test fails immediately if there is an error.
+func (db *DB) MustQuery(c sqlbuilder.Clause) *Result {
+	db.t.Helper()
+	res, err := db.Query(c)
+	if err != nil {
+		db.t.Fatalf("%s", err)
+	}
+	return res
+}
+
+// Insert the rows into the table, the test fails immediately if there is an error.
+func (db *DB) MustInsert(table string, columns []string, rows ...[]sqlbuilder.Arg) {
+	db.t.Helper()
+	db.MustExec(&sqlbuilder.Insert{Table: table, Columns: columns, Values: rows})
+}
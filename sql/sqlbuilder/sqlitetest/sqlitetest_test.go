@@ -0,0 +1,67 @@
+package sqlitetest_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/sqlitetest"
+)
+
+var schema = []sqlbuilder.Clause{
+	&sqlbuilder.CreateTable{Name: "flows", Columns: []sqlbuilder.ColumnDef{
+		{Name: "id", Type: "INTEGER", PrimaryKey: true},
+		{Name: "src", Type: "TEXT", NotNull: true},
+		{Name: "bytes", Type: "INTEGER"},
+	}},
+	sqlbuilder.NewSimpleClause(sqlbuilder.DontNewline, "CREATE INDEX idx_flows_src ON flows (src)"),
+}
+
+func TestDB(t *testing.T) {
+	t.Run("query", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := sqlitetest.Open(t, schema...)
+		db.MustInsert("flows", []string{"id", "src", "bytes"}, []sqlbuilder.Arg{1, "a", 10}, []sqlbuilder.Arg{2, "b", 20}, []sqlbuilder.Arg{3, "a", nil})
+
+		res := db.MustQuery(sqlbuilder.NewQuery("flows").
+			Select("src", "COUNT(*) AS n", "SUM(bytes) AS total").
+			GroupBy("src").
+			OrderBy("src"))
+		Expect(res.Columns).Should(Equal([]string{"src", "n", "total"}))
+		Expect(res.Rows).Should(Equal([][]any{{"a", int64(2), int64(10)}, {"b", int64(1), int64(20)}}))
+		Expect(res.Column("n")).Should(Equal([]any{int64(2), int64(1)}))
+		Expect(res.Column("missing")).Should(BeNil())
+		Expect(res.Maps()).Should(Equal([]map[string]any{
+			{"src": "a", "n": int64(2), "total": int64(10)},
+			{"src": "b", "n": int64(1), "total": int64(20)},
+		}))
+	})
+	t.Run("dml", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := sqlitetest.Open(t, schema...)
+		db.MustInsert("flows", []string{"id", "src"}, []sqlbuilder.Arg{1, "a"}, []sqlbuilder.Arg{2, "b"})
+
+		res := db.MustExec(&sqlbuilder.Update{
+			Table: "flows",
+			Set:   sqlbuilder.Set{Assignments: []string{"bytes = ?"}, Args: []sqlbuilder.Arg{5}},
+			Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewColumnCondition("src", "=", "a")}},
+		})
+		Expect(res.RowsAffected()).Should(BeEquivalentTo(1))
+		db.MustExec(&sqlbuilder.Delete{Table: "flows", Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewColumnCondition("bytes", "IS NULL")}}})
+		Expect(db.MustQuery(sqlbuilder.NewQuery("flows").Select("id", "bytes")).Rows).Should(Equal([][]any{{int64(1), int64(5)}}))
+	})
+	t.Run("isolated", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := sqlitetest.Open(t, schema...)
+		Expect(db.MustQuery(sqlbuilder.NewQuery("flows")).Rows).Should(BeEmpty())
+	})
+	t.Run("errors", func(t *testing.T) {
+		RegisterTestingT(t)
+		db := sqlitetest.Open(t, schema...)
+		_, err := db.Exec(&sqlbuilder.Insert{Table: "flows", Columns: []string{"id"}, Values: [][]sqlbuilder.Arg{{1}}})
+		Expect(err).Should(MatchError(ContainSubstring(`exec "INSERT INTO flows (id) VALUES (?) "`)))
+		_, err = db.Query(sqlbuilder.NewQuery("flows").Lock(sqlbuilder.Lock{Strength: sqlbuilder.ForUpdate}))
+		Expect(err).Should(MatchError(sqlbuilder.ErrUnsupported))
+	})
+}
@@ -0,0 +1,255 @@
+package sqlbuilder
+
+import (
+	"io"
+	"strings"
+)
+
+// KeywordCase decides the case of keywords written by the built-in clauses.
+type KeywordCase int
+
+const (
+	UpperKeywords KeywordCase = iota // Such as "SELECT".
+	LowerKeywords                    // Such as "select".
+)
+
+// CommaPosition decides where the comma is placed between the items of a list in Format mode.
+type CommaPosition int
+
+const (
+	TrailingComma CommaPosition = iota // "a,\n  b"
+	LeadingComma                       // "a\n  , b"
+)
+
+/*
+Style is the formatting policy of the SQL, it's carried by the sqlWriter implemented StyledWriter.
+The levels Format and Compact are still the presets of layout:
+the indentation, line endings, comma placement and line width only take effect in Format mode,
+and the keyword case takes effect in both modes.
+The lists of SELECT and SET are laid out by the comma placement and line width,
+the keywords are cased for the built-in statements, conditions and SimpleClause.Keyword,
+but the SQL strings provided by users such as SimpleClause.SQL are never changed.
+*/
+type Style struct {
+	// The indentation of each level, such as "\t" or four spaces. It's two spaces if empty.
+	Indent string
+	// The line ending, it's "\n" if empty.
+	EOL      string
+	Keywords KeywordCase
+	Comma    CommaPosition
+	// The items of lists are packed into lines up to the width in Format mode,
+	// an item is never broken, and there is one item per line if it is 0.
+	MaxWidth int
+}
+
+// Get the style used when the sqlWriter is not a StyledWriter, it can not be changed.
+func DefaultStyle() Style {
+	return Style{
+		Indent: singleSpace,
+		EOL:    EOL,
+	}
+}
+
+// A sqlWriter implemented StyledWriter carries the style when parsing the SQL.
+// The sqlWriter which is not a StyledWriter or whose Style is nil will be formatted by DefaultStyle.
+type StyledWriter interface {
+	io.StringWriter
+	Style() *Style
+}
+
+// Get a copy of the style carried by the sqlWriter.
+func StyleOf(sqlWriter io.StringWriter) Style {
+	if w, ok := sqlWriter.(StyledWriter); ok {
+		if style := w.Style(); style != nil {
+			return *style
+		}
+	}
+	return DefaultStyle()
+}
+
+type styledWriter struct {
+	io.StringWriter
+	style *Style
+}
+
+func (w *styledWriter) Style() *Style {
+	return w.style
+}
+
+// Wrap the sqlWriter to carry the style, the empty fields of the style are filled by DefaultStyle.
+func NewStyledWriter(sqlWriter io.StringWriter, style Style) StyledWriter {
+	if w, ok := sqlWriter.(*styledWriter); ok {
+		sqlWriter = w.StringWriter
+	}
+	style.Indent, style.EOL = style.indent(), style.eol()
+	return &styledWriter{StringWriter: sqlWriter, style: &style}
+}
+
+// Get the indentation of the level.
+func (s Style) Space(level int) string {
+	if CompactLevel(level) {
+		return ""
+	}
+	indent := s.indent()
+	if indent == singleSpace {
+		return GetSpace(level)
+	}
+	return strings.Repeat(indent, level)
+}
+
+// Get the keyword in the case of the style.
+func (s Style) Keyword(keyword string) string {
+	if s.Keywords == LowerKeywords {
+		return strings.ToLower(keyword)
+	}
+	return keyword
+}
+
+func (s Style) indent() string {
+	if s.Indent == "" {
+		return singleSpace
+	}
+	return s.Indent
+}
+
+func (s Style) eol() string {
+	if s.EOL == "" {
+		return EOL
+	}
+	return s.EOL
+}
+
+// Write the keyword in the case of the style carried by the sqlWriter, such as "SELECT" or " AND ".
+func WriteKeyword(sqlWriter io.StringWriter, keyword string) error {
+	return WriteString(sqlWriter, StyleOf(sqlWriter).Keyword(keyword))
+}
+
+// Build the clause with the style, see Build.
+func BuildStyle(c Clause, dialect Dialect, level int, style Style) (string, []Arg, error) {
+	var buff strings.Builder
+	args := NewArgList(dialect, 0)
+	if err := c.Parse(NewStyledWriter(&buff, style), args, level); err != nil {
+		return "", nil, locateRoot(err, c)
+	}
+	return buff.String(), args.Args, nil
+}
+
+/*
+listWriter writes the items of a list, such as the columns of SELECT, in lines according to the style.
+In Compact mode, each item is followed by a comma except the last one, and a space.
+In Format mode, each line is indented by the level, and the last line is ended by finish.
+*/
+type listWriter struct {
+	sqlWriter io.StringWriter
+	style     Style
+	level     int
+	count     int
+	index     int
+	width     int
+}
+
+func newListWriter(sqlWriter io.StringWriter, level, count int) *listWriter {
+	return &listWriter{
+		sqlWriter: sqlWriter,
+		style:     StyleOf(sqlWriter),
+		level:     level,
+		count:     count,
+	}
+}
+
+// Whether the width of items is needed by begin.
+func (l *listWriter) measure() bool {
+	return l.style.MaxWidth > 0 && !CompactLevel(l.level)
+}
+
+// Begin the next item of size bytes, the size is used only if measure returns true.
+func (l *listWriter) begin(size int) error {
+	if CompactLevel(l.level) {
+		return nil
+	}
+	leading := l.style.Comma == LeadingComma && l.index != 0
+	if l.index != 0 && l.measure() {
+		sep, need := " ", l.width+1+size
+		if leading {
+			sep, need = ", ", l.width+2+size
+		} else if l.index != l.count-1 {
+			need++
+		}
+		if need <= l.style.MaxWidth {
+			l.width += len(sep) + size
+			return WriteString(l.sqlWriter, sep)
+		}
+	}
+	if l.index != 0 {
+		if err := WriteString(l.sqlWriter, l.style.eol()); err != nil {
+			return err
+		}
+	}
+	space := l.style.Space(l.level)
+	if err := WriteString(l.sqlWriter, space); err != nil {
+		return err
+	}
+	l.width = len(space) + size
+	if leading {
+		l.width += 2
+		return WriteString(l.sqlWriter, ", ")
+	}
+	return nil
+}
+
+// End the current item.
+func (l *listWriter) end() error {
+	last := l.index == l.count-1
+	l.index++
+	if CompactLevel(l.level) {
+		if !last {
+			if err := WriteString(l.sqlWriter, ","); err != nil {
+				return err
+			}
+		}
+		return WriteString(l.sqlWriter, Space)
+	}
+	if !last && l.style.Comma == TrailingComma {
+		l.width++
+		return WriteString(l.sqlWriter, ",")
+	}
+	return nil
+}
+
+// Write the item as a string.
+func (l *listWriter) item(str string) error {
+	if err := l.begin(len(str)); err != nil {
+		return err
+	}
+	if err := WriteString(l.sqlWriter, str); err != nil {
+		return err
+	}
+	return l.end()
+}
+
+// Write the item parsed by fn, it's parsed into a temporary buffer to measure the width if needed.
+func (l *listWriter) parse(fn func(sqlWriter io.StringWriter) error) error {
+	if !l.measure() {
+		if err := l.begin(0); err != nil {
+			return err
+		}
+		if err := fn(l.sqlWriter); err != nil {
+			return err
+		}
+		return l.end()
+	}
+	var buff strings.Builder
+	style := l.style
+	if err := fn(&styledWriter{StringWriter: &buff, style: &style}); err != nil {
+		return err
+	}
+	return l.item(buff.String())
+}
+
+// End the last line in Format mode.
+func (l *listWriter) finish() error {
+	if CompactLevel(l.level) || l.count == 0 {
+		return nil
+	}
+	return WriteString(l.sqlWriter, l.style.eol())
+}
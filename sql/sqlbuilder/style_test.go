@@ -0,0 +1,134 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/expr"
+)
+
+func styleQuery() sqlbuilder.Query {
+	return sqlbuilder.NewQuery("flows").
+		Select("id", "src", "dst").
+		Join("LEFT JOIN", sqlbuilder.TableByName("hosts"), sqlbuilder.NewCondition("hosts.id = flows.host_id")).
+		Where(sqlbuilder.NewColumnCondition("src", "IN", 1, 2), sqlbuilder.Not(sqlbuilder.NewCondition("dst IS NULL"), sqlbuilder.OmitBrackets)).
+		OrderBy("id").
+		Limit("10")
+}
+
+func TestStyle(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		RegisterTestingT(t)
+		sql, _, err := sqlbuilder.BuildStyle(styleQuery(), sqlbuilder.Generic, sqlbuilder.Format, sqlbuilder.Style{})
+		Expect(err).ShouldNot(HaveOccurred())
+		expected, _, err := sqlbuilder.Build(styleQuery(), sqlbuilder.Generic, sqlbuilder.Format)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal(expected))
+
+		style := sqlbuilder.DefaultStyle()
+		style.Keywords = sqlbuilder.LowerKeywords
+		Expect(sqlbuilder.DefaultStyle().Keywords).Should(Equal(sqlbuilder.UpperKeywords))
+		sql, _, err = sqlbuilder.Build(styleQuery(), sqlbuilder.Generic, sqlbuilder.Format)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal(expected))
+	})
+	t.Run("tabs and lower keywords", func(t *testing.T) {
+		RegisterTestingT(t)
+		style := sqlbuilder.Style{Indent: "\t", Keywords: sqlbuilder.LowerKeywords}
+		sql, args, err := sqlbuilder.BuildStyle(styleQuery(), sqlbuilder.PostgreSQL, sqlbuilder.Format, style)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("select\n\tid,\n\tsrc,\n\tdst\nfrom flows\nleft join hosts on hosts.id = flows.host_id\n" +
+			"where\n\tsrc in ($1, $2)\n\tand not dst IS NULL\norder by id\nlimit 10\n"))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{1, 2}))
+
+		sql, _, err = sqlbuilder.BuildStyle(styleQuery(), sqlbuilder.Generic, sqlbuilder.Compact, style)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("select id, src, dst from flows left join hosts on hosts.id = flows.host_id where src in (?, ?) and not dst IS NULL order by id limit 10 "))
+	})
+	t.Run("leading comma", func(t *testing.T) {
+		RegisterTestingT(t)
+		style := sqlbuilder.Style{Indent: "    ", Comma: sqlbuilder.LeadingComma, EOL: "\r\n"}
+		update := &sqlbuilder.Update{Table: "flows", Set: sqlbuilder.Set{Assignments: []string{"a = ?", "b = ?"}, Args: []sqlbuilder.Arg{1, 2}}}
+		sql, _, err := sqlbuilder.BuildStyle(update, sqlbuilder.Generic, sqlbuilder.Format, style)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("UPDATE flows\r\nSET\r\n    a = ?\r\n    , b = ?\r\n"))
+
+		sql, _, err = sqlbuilder.BuildStyle(update, sqlbuilder.Generic, sqlbuilder.Compact, style)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("UPDATE flows SET a = ?, b = ? "))
+	})
+	t.Run("max width", func(t *testing.T) {
+		RegisterTestingT(t)
+		q := sqlbuilder.NewQuery("flows").
+			Select("id", "src_ip", "dst_ip", "src_port", "dst_port").
+			SelectExpr(sqlbuilder.NewColumn[int]("flows", "protocol"))
+		sql, _, err := sqlbuilder.BuildStyle(q, sqlbuilder.Generic, sqlbuilder.Format, sqlbuilder.Style{MaxWidth: 30})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("SELECT\n  id, src_ip, dst_ip,\n  src_port, dst_port,\n  flows.protocol\nFROM flows\n"))
+
+		sql, _, err = sqlbuilder.BuildStyle(q, sqlbuilder.Generic, sqlbuilder.Format, sqlbuilder.Style{MaxWidth: 30, Comma: sqlbuilder.LeadingComma})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("SELECT\n  id, src_ip, dst_ip, src_port\n  , dst_port, flows.protocol\nFROM flows\n"))
+
+		sql, _, err = sqlbuilder.BuildStyle(q, sqlbuilder.Generic, sqlbuilder.Format, sqlbuilder.Style{MaxWidth: 1})
+		Expect(err).ShouldNot(HaveOccurred())
+		expected, _, err := sqlbuilder.Build(q, sqlbuilder.Generic, sqlbuilder.Format)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal(expected))
+	})
+	t.Run("renderer", func(t *testing.T) {
+		RegisterTestingT(t)
+		r := sqlbuilder.NewRenderer(sqlbuilder.MySQL, sqlbuilder.Format)
+		r.Style = &sqlbuilder.Style{Keywords: sqlbuilder.LowerKeywords}
+		q := sqlbuilder.NewQuery("flows").Where(sqlbuilder.NewCondition("id = ?", 1)).Lock(sqlbuilder.Lock{Strength: sqlbuilder.ForUpdate, Wait: sqlbuilder.SkipLocked})
+		sql, _, err := r.Build(q)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("select *\nfrom flows\nwhere\n  id = ?\nfor update skip locked\n"))
+	})
+	style := sqlbuilder.Style{Keywords: sqlbuilder.LowerKeywords, Comma: sqlbuilder.LeadingComma}
+	t.Run("ddl", func(t *testing.T) {
+		RegisterTestingT(t)
+		create := &sqlbuilder.CreateTable{Name: "flows", IfNotExists: true, Columns: []sqlbuilder.ColumnDef{
+			{Name: "id", Type: "BIGINT", NotNull: true, PrimaryKey: true},
+			{Name: "name", Type: "TEXT", Default: "''"},
+		}, Constraints: []string{"UNIQUE (name)"}}
+		sql, _, err := sqlbuilder.BuildStyle(create, sqlbuilder.PostgreSQL, sqlbuilder.Format, style)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("create table if not exists flows (\n  id BIGINT not null primary key\n  , name TEXT default ''\n  , UNIQUE (name)\n)\n"))
+		alter := &sqlbuilder.AlterTable{Name: "flows", Actions: []sqlbuilder.AlterAction{
+			&sqlbuilder.AddColumn{Column: sqlbuilder.ColumnDef{Name: "mtu", Type: "INT"}},
+			&sqlbuilder.RenameColumn{Name: "src", NewName: "src_ip"},
+		}}
+		sql, _, err = sqlbuilder.BuildStyle(alter, sqlbuilder.PostgreSQL, sqlbuilder.Format, style)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("alter table flows\n  add column mtu INT\n  , rename column src to src_ip\n"))
+		index := &sqlbuilder.CreateIndex{Name: "idx_name", Table: "flows", Unique: true, Columns: []string{"name"}}
+		sql, _, err = sqlbuilder.BuildStyle(index, sqlbuilder.PostgreSQL, sqlbuilder.Compact, style)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("create unique index idx_name on flows (name) "))
+		sql, _, err = sqlbuilder.BuildStyle(&sqlbuilder.DropTable{Name: "flows", Cascade: true}, sqlbuilder.PostgreSQL, sqlbuilder.Compact, style)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("drop table flows cascade "))
+	})
+	t.Run("insert", func(t *testing.T) {
+		RegisterTestingT(t)
+		insert := &sqlbuilder.Insert{Table: "flows", Columns: []string{"id", "name"}, Values: [][]sqlbuilder.Arg{{1, "a"}, {2, "b"}}}
+		sql, args, err := sqlbuilder.BuildStyle(insert, sqlbuilder.PostgreSQL, sqlbuilder.Format, style)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("insert into flows (id, name)\nvalues\n  ($1, $2)\n  , ($3, $4)\n"))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{1, "a", 2, "b"}))
+	})
+	t.Run("expressions", func(t *testing.T) {
+		RegisterTestingT(t)
+		q := sqlbuilder.NewQuery("flows").SelectExpr(
+			expr.As(expr.CastAs(expr.Col("mtu"), "TEXT"), "m"),
+			expr.CaseWhen(expr.Cmp(expr.Col("id"), "IS NOT", expr.Raw("NULL")), expr.Val(1)).Otherwise(expr.Call("COUNT", expr.Distinct{Expression: expr.Col("src")})),
+		)
+		sql, args, err := sqlbuilder.BuildStyle(q, sqlbuilder.PostgreSQL, sqlbuilder.Compact, style)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sql).Should(Equal("select cast(mtu as TEXT) as m, case when id is not NULL then $1 else COUNT(distinct src) end from flows "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{1}))
+	})
+}
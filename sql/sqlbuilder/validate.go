@@ -0,0 +1,236 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The option of Validate.
+type ValidateOption func(v *validator)
+
+// Report LIMIT without ORDER BY, the rows returned are nondeterministic.
+func StrictLimit() ValidateOption {
+	return func(v *validator) {
+		v.strictLimit = true
+	}
+}
+
+/*
+Validate the structure of the clause tree before rendering, all problems are returned at once by errors.Join.
+Each problem is a *ParseError located from the type of the root, the problems of built-in clauses are ErrInvalidStructure.
+The built-in statements and clauses are validated, including the sub queries in them,
+other clauses are validated if they implement Validate(opts ...ValidateOption) error.
+*/
+func ValidateClause(c Clause, opts ...ValidateOption) error {
+	if c == nil {
+		return NewInvalidError("nil clause")
+	}
+	v := &validator{}
+	for _, opt := range opts {
+		opt(v)
+	}
+	v.clause("", c)
+	for i := range v.errs {
+		v.errs[i] = locateRoot(v.errs[i], c)
+	}
+	return errors.Join(v.errs...)
+}
+
+func (q Query) Validate(opts ...ValidateOption) error {
+	return ValidateClause(q, opts...)
+}
+
+func (l *DQL) Validate(opts ...ValidateOption) error {
+	return ValidateClause(l, opts...)
+}
+
+func (l *Update) Validate(opts ...ValidateOption) error {
+	return ValidateClause(l, opts...)
+}
+
+func (l *Delete) Validate(opts ...ValidateOption) error {
+	return ValidateClause(l, opts...)
+}
+
+func (l *Insert) Validate(opts ...ValidateOption) error {
+	return ValidateClause(l, opts...)
+}
+
+func (c *WithClause) Validate(opts ...ValidateOption) error {
+	return ValidateClause(c, opts...)
+}
+
+type validator struct {
+	strictLimit bool
+	errs        []error
+}
+
+func (v *validator) report(path string, format string, args ...any) {
+	v.errs = append(v.errs, Locate(NewInvalidError(format, args...), path))
+}
+
+func (v *validator) clause(path string, c Clause) {
+	switch c := c.(type) {
+	case nil:
+		v.report(path, "nil clause")
+	case *DQL:
+		v.dql(path, c)
+	case Query:
+		if c.dql != nil {
+			v.dql(path, c.dql)
+		}
+	case *Update:
+		v.with(joinPath(path, "With"), c.With)
+		v.tableName(path, c.Table)
+		if len(c.Set.Assignments) == 0 {
+			v.report(joinPath(path, "Set"), "empty SET")
+		}
+		v.clauses(joinPath(path, "Additional"), c.Additional)
+	case *Delete:
+		v.with(joinPath(path, "With"), c.With)
+		v.tableName(path, c.Table)
+		v.clauses(joinPath(path, "Additional"), c.Additional)
+	case *Insert:
+		v.insert(path, c)
+	case *WithClause:
+		v.withClause(path, c)
+	case From:
+		v.table(joinPath(path, "Table"), c.Table, true)
+	case *Join:
+		v.join(path, c)
+	case *Clauses:
+		v.clauses(path, *c)
+	case *Explain:
+		v.clause(joinPath(path, "Clause"), c.Clause)
+	case interface {
+		Validate(opts ...ValidateOption) error
+	}:
+		if err := c.Validate(v.options()...); err != nil {
+			v.errs = append(v.errs, Locate(err, path))
+		}
+	}
+}
+
+func (v *validator) options() []ValidateOption {
+	if v.strictLimit {
+		return []ValidateOption{StrictLimit()}
+	}
+	return nil
+}
+
+func (v *validator) dql(path string, l *DQL) {
+	if l == nil {
+		v.report(path, "nil DQL")
+		return
+	}
+	v.with(joinPath(path, "With"), l.With)
+	if l.From.Valid() {
+		v.table(joinPath(path, "From.Table"), l.From.Table, true)
+	}
+	for i := range l.Joins {
+		v.join(joinPath(path, fmt.Sprintf("Joins[%d]", i)), &l.Joins[i])
+	}
+	if hasConditions(l.Having.Conditions) && l.Group == nil {
+		v.report(joinPath(path, "Having"), "HAVING without GROUP BY")
+	}
+	if v.strictLimit && l.Limit != nil && l.Order == nil {
+		v.report(joinPath(path, "Limit"), "LIMIT without ORDER BY")
+	}
+	v.clauses(joinPath(path, "Additional"), l.Additional)
+}
+
+func (v *validator) join(path string, join *Join) {
+	if join.Kind == "" {
+		v.report(path, "empty kind of JOIN")
+	}
+	if !join.Table.Valid() {
+		v.report(path, "empty table of JOIN")
+	} else {
+		v.table(joinPath(path, "Table"), join.Table, false)
+		if join.Table.Clause != nil && join.Table.Name != "" && join.Table.NamePosition == NameFirst {
+			v.report(joinPath(path, "Table"), "NameFirst is not supported by JOIN")
+		}
+	}
+}
+
+func (v *validator) insert(path string, l *Insert) {
+	v.with(joinPath(path, "With"), l.With)
+	v.tableName(path, l.Table)
+	switch {
+	case l.Query != nil && len(l.Values) != 0:
+		v.report(path, "both Values and Query are set")
+	case l.Query != nil:
+		v.clause(joinPath(path, "Query"), l.Query)
+	case len(l.Values) == 0:
+		v.report(joinPath(path, "Values"), "no rows to insert")
+	}
+	for i, row := range l.Values {
+		if len(l.Columns) != 0 && len(row) != len(l.Columns) {
+			v.report(joinPath(path, fmt.Sprintf("Values[%d]", i)), "row has %d values, but %d columns are expected", len(row), len(l.Columns))
+		}
+	}
+	v.clauses(joinPath(path, "Additional"), l.Additional)
+}
+
+func (v *validator) with(path string, with With) {
+	if with != nil {
+		v.clause(path, with)
+	}
+}
+
+func (v *validator) withClause(path string, c *WithClause) {
+	if len(c.Tables) == 0 {
+		v.report(path, "empty WITH")
+	}
+	names := make(map[string]bool, len(c.Tables))
+	for i, t := range c.Tables {
+		tablePath := joinPath(path, fmt.Sprintf("Tables[%d]", i))
+		if t.Name == "" {
+			v.report(tablePath, "empty name of common table expression")
+		} else if names[t.Name] {
+			v.report(tablePath, "duplicate common table expression %q", t.Name)
+		}
+		names[t.Name] = true
+		if t.Clause != nil {
+			v.namePosition(tablePath, t.NamePosition)
+			v.clause(tablePath+".Clause", t.Clause)
+		}
+	}
+}
+
+// Validate the table in FROM or JOIN, the sub query without name is allowed in FROM only if allowUnnamed is true.
+func (v *validator) table(path string, t Table, allowUnnamed bool) {
+	if t.Clause == nil {
+		return
+	}
+	if t.Name == "" {
+		if !allowUnnamed {
+			v.report(path, "empty name of sub query")
+		}
+	} else {
+		v.namePosition(path, t.NamePosition)
+	}
+	v.clause(path+".Clause", t.Clause)
+}
+
+func (v *validator) namePosition(path string, position NamePosition) {
+	if position != NameFirst && position != NameAfter {
+		v.report(path, "bad NamePosition %d", position)
+	}
+}
+
+func (v *validator) tableName(path, name string) {
+	if name == "" {
+		v.report(joinPath(path, "Table"), "empty table name")
+	}
+}
+
+// The nil clauses are skipped as Clauses.Parse does.
+func (v *validator) clauses(path string, cs Clauses) {
+	for i, c := range cs {
+		if c == nil {
+			continue
+		}
+		v.clause(joinPath(path, fmt.Sprintf("[%d]", i)), c)
+	}
+}
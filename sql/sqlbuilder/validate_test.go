@@ -0,0 +1,79 @@
+package sqlbuilder_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		RegisterTestingT(t)
+		q := sqlbuilder.NewQuery("flows").
+			With(sqlbuilder.NameAsTable("recent", sqlbuilder.NewQuery("flows").Where(sqlbuilder.NewCondition("time > ?", 1)))).
+			Join("JOIN", sqlbuilder.TableByName("recent"), sqlbuilder.NewCondition("recent.id = flows.id")).
+			GroupBy("flows.src").
+			Having(sqlbuilder.NewCondition("COUNT(*) > ?", 1)).
+			OrderBy("flows.src").
+			Limit("10")
+		Expect(q.Validate(sqlbuilder.StrictLimit())).Should(Succeed())
+		Expect(sqlbuilder.NewQuery("flows").Limit("10").Validate()).Should(Succeed())
+		Expect(sqlbuilder.ValidateClause(sqlbuilder.FromTable(sqlbuilder.NewQuery("flows")))).Should(Succeed())
+	})
+	t.Run("all problems", func(t *testing.T) {
+		RegisterTestingT(t)
+		dql := &sqlbuilder.DQL{
+			With: &sqlbuilder.WithClause{Tables: []sqlbuilder.Table{
+				sqlbuilder.NameAsTable("t", sqlbuilder.NewQuery("flows")),
+				sqlbuilder.NameAsTable("t", sqlbuilder.NewQuery("flows")),
+				{Clause: sqlbuilder.NewQuery("flows"), NamePosition: 2},
+			}},
+			From: sqlbuilder.FromTable(&sqlbuilder.DQL{
+				With:   &sqlbuilder.WithClause{},
+				From:   sqlbuilder.FromTableName("flows"),
+				Having: sqlbuilder.HavingClause{Conditions: []sqlbuilder.Condition{sqlbuilder.NewCondition("COUNT(*) > 1")}},
+			}),
+			Joins: []sqlbuilder.Join{{Kind: "JOIN", Table: sqlbuilder.TableByClause(sqlbuilder.NewQuery("t")), On: []sqlbuilder.Condition{nil}}},
+			Limit: sqlbuilder.MakeLimit("10"),
+		}
+		err := dql.Validate(sqlbuilder.StrictLimit())
+		Expect(errors.Is(err, sqlbuilder.ErrInvalidStructure)).Should(BeTrue())
+		Expect(err).Should(MatchError(
+			`DQL.With.Tables[1]: duplicate common table expression "t"` + "\n" +
+				"DQL.With.Tables[2]: empty name of common table expression\n" +
+				"DQL.With.Tables[2]: bad NamePosition 2\n" +
+				"DQL.From.Table.Clause.With: empty WITH\n" +
+				"DQL.From.Table.Clause.Having: HAVING without GROUP BY\n" +
+				"DQL.Joins[0].Table: empty name of sub query\n" +
+				"DQL.Limit: LIMIT without ORDER BY",
+		))
+		Expect(dql.Validate()).ShouldNot(MatchError(ContainSubstring("LIMIT")))
+	})
+	t.Run("query", func(t *testing.T) {
+		RegisterTestingT(t)
+		err := sqlbuilder.NewQuery("flows").Having(sqlbuilder.NewCondition("COUNT(*) > 1")).Validate()
+		Expect(err).Should(MatchError("Query.Having: HAVING without GROUP BY"))
+	})
+	t.Run("dml", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect((&sqlbuilder.Update{}).Validate()).Should(MatchError("Update.Table: empty table name\nUpdate.Set: empty SET"))
+		Expect((&sqlbuilder.Delete{Table: "flows", With: &sqlbuilder.WithClause{}}).Validate()).Should(MatchError("Delete.With: empty WITH"))
+		Expect((&sqlbuilder.Insert{Table: "flows"}).Validate()).Should(MatchError("Insert.Values: no rows to insert"))
+		Expect((&sqlbuilder.Insert{
+			Table:   "flows",
+			Columns: []string{"id"},
+			Values:  [][]sqlbuilder.Arg{{1}, {1, 2}},
+			Query:   sqlbuilder.NewQuery("flows"),
+		}).Validate()).Should(MatchError("Insert: both Values and Query are set\nInsert.Values[1]: row has 2 values, but 1 columns are expected"))
+	})
+	t.Run("nested clauses", func(t *testing.T) {
+		RegisterTestingT(t)
+		cs := sqlbuilder.Clauses{sqlbuilder.NewSimpleClause(sqlbuilder.AutoNewline, "SELECT 1"), &sqlbuilder.WithClause{}, nil}
+		Expect(sqlbuilder.ValidateClause(&cs)).Should(MatchError("Clauses[1]: empty WITH"))
+		Expect(sqlbuilder.ValidateClause(&sqlbuilder.Explain{})).Should(MatchError("Explain.Clause: nil clause"))
+		Expect(sqlbuilder.ValidateClause(nil)).Should(MatchError(sqlbuilder.ErrInvalidStructure))
+	})
+}
@@ -0,0 +1,438 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Node is a Clause, a Condition or an Expression.
+type Node any
+
+// Cursor describes a node encountered during Apply.
+type Cursor struct {
+	node   Node
+	parent Node
+	name   string
+	index  int
+}
+
+// The current node.
+func (c *Cursor) Node() Node {
+	return c.node
+}
+
+// The parent of the current node, it's nil for the root node.
+func (c *Cursor) Parent() Node {
+	return c.parent
+}
+
+// The field name of the current node in its parent, such as "Where", "L" or "Tables.Clause".
+func (c *Cursor) Name() string {
+	return c.name
+}
+
+// The index of the current node if it's an element of a slice in its parent, or -1.
+func (c *Cursor) Index() int {
+	return c.index
+}
+
+// Replace the current node, the children of the new node will be traversed.
+// The new node must be able to be stored into the field of the parent, or Apply returns an error.
+func (c *Cursor) Replace(node Node) {
+	c.node = node
+}
+
+// ApplyFunc is called for each node during Apply, see Apply for the meaning of the result.
+type ApplyFunc func(cursor *Cursor) bool
+
+/*
+Apply traverses the node tree recursively, the node must be a Clause, a Condition or an Expression.
+For each node, pre is called before its children are traversed, and post is called after.
+If pre returns false, the children and post of the node are skipped.
+If post returns false, the traversal is terminated.
+Nodes replaced by Cursor.Replace are stored into their parents, and the new root is returned.
+Note: The fields of pointer nodes such as *DQL are modified in place, clone the tree first if it is shared.
+Clauses and conditions not implemented in this package are leaves, such as CustomClause, SimpleClause and SimpleCondition,
+so are the expressions not implementing ParentExpression. The SQL in their strings can not be traversed.
+*/
+func Apply(root Node, pre, post ApplyFunc) (Node, error) {
+	a := applier{pre: pre, post: post}
+	return a.apply(nil, "", -1, root)
+}
+
+// Apply for a Clause, see Apply.
+func ApplyClause(root Clause, pre, post ApplyFunc) (Clause, error) {
+	node, err := Apply(root, pre, post)
+	if err != nil {
+		return nil, err
+	}
+	return asClause(node, "")
+}
+
+// Apply for a Condition, see Apply.
+func ApplyCondition(root Condition, pre, post ApplyFunc) (Condition, error) {
+	node, err := Apply(root, pre, post)
+	if err != nil {
+		return nil, err
+	}
+	return asCondition(node, "")
+}
+
+// Inspect traverses the node tree in depth-first order, the children of a node are skipped if fn returns false.
+func Inspect(root Node, fn func(node Node) bool) {
+	_, _ = Apply(root, func(cursor *Cursor) bool {
+		return fn(cursor.Node())
+	}, nil)
+}
+
+type applier struct {
+	pre  ApplyFunc
+	post ApplyFunc
+	stop bool
+}
+
+func (a *applier) apply(parent Node, name string, index int, node Node) (Node, error) {
+	if node == nil || a.stop {
+		return node, nil
+	}
+	cursor := Cursor{node: node, parent: parent, name: name, index: index}
+	if a.pre != nil && !a.pre(&cursor) {
+		return cursor.node, nil
+	}
+	node, err := a.children(cursor.node)
+	if err != nil {
+		return nil, err
+	}
+	cursor.node = node
+	if a.post != nil && !a.stop && !a.post(&cursor) {
+		a.stop = true
+	}
+	return cursor.node, nil
+}
+
+func (a *applier) clause(parent Node, name string, index int, c Clause) (Clause, error) {
+	if c == nil {
+		return nil, nil
+	}
+	node, err := a.apply(parent, name, index, c)
+	if err != nil {
+		return nil, err
+	}
+	return asClause(node, name)
+}
+
+func (a *applier) condition(parent Node, name string, index int, c Condition) (Condition, error) {
+	if c == nil {
+		return nil, nil
+	}
+	node, err := a.apply(parent, name, index, c)
+	if err != nil {
+		return nil, err
+	}
+	return asCondition(node, name)
+}
+
+func (a *applier) conditions(parent Node, name string, cs []Condition) error {
+	for i := range cs {
+		c, err := a.condition(parent, name, i, cs[i])
+		if err != nil {
+			return err
+		}
+		cs[i] = c
+	}
+	return nil
+}
+
+// Expressions share the method of conditions, so they are traversed as conditions.
+func (a *applier) expressions(parent Node, name string, es []Expression) error {
+	for i := range es {
+		e, err := a.condition(parent, name, i, es[i])
+		if err != nil {
+			return err
+		}
+		es[i] = e
+	}
+	return nil
+}
+
+func (a *applier) clauses(parent Node, name string, cs []Clause) error {
+	for i := range cs {
+		c, err := a.clause(parent, name, i, cs[i])
+		if err != nil {
+			return err
+		}
+		cs[i] = c
+	}
+	return nil
+}
+
+func (a *applier) tables(parent Node, name string, ts []Table) error {
+	for i := range ts {
+		c, err := a.clause(parent, name, i, ts[i].Clause)
+		if err != nil {
+			return err
+		}
+		ts[i].Clause = c
+	}
+	return nil
+}
+
+func (a *applier) children(node Node) (Node, error) {
+	var err error
+	switch n := node.(type) {
+	case *DQL:
+		return n, a.dql(n)
+	case *Update:
+		return n, a.dml(n, &n.With, &n.Where, n.Additional)
+	case *Delete:
+		return n, a.dml(n, &n.With, &n.Where, n.Additional)
+	case *Insert:
+		if n.With, err = a.clause(n, "With", -1, n.With); err != nil {
+			return nil, err
+		}
+		if n.Query, err = a.clause(n, "Query", -1, n.Query); err != nil {
+			return nil, err
+		}
+		return n, a.clauses(n, "Additional", n.Additional)
+	case From:
+		n.Table.Clause, err = a.clause(n, "Table.Clause", -1, n.Table.Clause)
+		return n, err
+	case *From:
+		n.Table.Clause, err = a.clause(n, "Table.Clause", -1, n.Table.Clause)
+		return n, err
+	case WhereClause:
+		return n, a.conditions(n, "Conditions", n.Conditions)
+	case *WhereClause:
+		return n, a.conditions(n, "Conditions", n.Conditions)
+	case HavingClause:
+		return n, a.conditions(n, "Conditions", n.Conditions)
+	case *HavingClause:
+		return n, a.conditions(n, "Conditions", n.Conditions)
+	case *Join:
+		if n.Table.Clause, err = a.clause(n, "Table.Clause", -1, n.Table.Clause); err != nil {
+			return nil, err
+		}
+		return n, a.conditions(n, "On", n.On)
+	case *WithClause:
+		return n, a.tables(n, "Tables.Clause", n.Tables)
+	case *Select:
+		return n, a.expressions(n, "Expressions", n.Expressions)
+	case *expressionsClause:
+		return n, a.expressions(n, "Expressions", n.exprs)
+	case OrderTerm:
+		n.Expression, err = a.condition(n, "Expression", -1, n.Expression)
+		return n, err
+	case SubQuery:
+		n.Clause, err = a.clause(n, "Clause", -1, n.Clause)
+		return n, err
+	case ExistsCondition:
+		n.SubQuery.Clause, err = a.clause(n, "SubQuery.Clause", -1, n.SubQuery.Clause)
+		return n, err
+	case *Clauses:
+		return n, a.clauses(n, "", *n)
+	case *addLeveledClause:
+		n.clause, err = a.clause(n, "Clause", -1, n.clause)
+		return n, err
+	case BracketedCondition:
+		n.Condition, err = a.condition(n, "Condition", -1, n.Condition)
+		return n, err
+	case NotCondition:
+		n.Condition, err = a.condition(n, "Condition", -1, n.Condition)
+		return n, err
+	case ScopeCondition:
+		n.Condition, err = a.condition(n, "Condition", -1, n.Condition)
+		return n, err
+	case AndCondition:
+		if n.L, err = a.condition(n, "L", -1, n.L); err != nil {
+			return nil, err
+		}
+		n.R, err = a.condition(n, "R", -1, n.R)
+		return n, err
+	case OrCondition:
+		if n.L, err = a.condition(n, "L", -1, n.L); err != nil {
+			return nil, err
+		}
+		n.R, err = a.condition(n, "R", -1, n.R)
+		return n, err
+	case ParentExpression:
+		children := n.Children()
+		if len(children) == 0 {
+			return n, nil
+		}
+		if err = a.expressions(n, "Children", children); err != nil {
+			return nil, err
+		}
+		return n.WithChildren(children), nil
+	default:
+		return node, nil
+	}
+}
+
+func (a *applier) dql(l *DQL) error {
+	var (
+		node Node
+		err  error
+	)
+	if l.With, err = a.clause(l, "With", -1, l.With); err != nil {
+		return err
+	}
+	if node, err = a.apply(l, "Select", -1, &l.Select); err != nil {
+		return err
+	}
+	if s, ok := node.(*Select); ok {
+		l.Select = *s
+	} else {
+		return unexpectedNode(node, "Select")
+	}
+	if node, err = a.apply(l, "From", -1, l.From); err != nil {
+		return err
+	}
+	if l.From, err = asValue[From](node, "From"); err != nil {
+		return err
+	}
+	for i := range l.Joins {
+		if node, err = a.apply(l, "Joins", i, &l.Joins[i]); err != nil {
+			return err
+		}
+		if l.Joins[i], err = asValue[Join](node, "Joins"); err != nil {
+			return err
+		}
+	}
+	if node, err = a.apply(l, "Where", -1, l.Where); err != nil {
+		return err
+	}
+	if l.Where, err = asValue[WhereClause](node, "Where"); err != nil {
+		return err
+	}
+	if l.Group, err = a.clause(l, "Group", -1, l.Group); err != nil {
+		return err
+	}
+	if node, err = a.apply(l, "Having", -1, l.Having); err != nil {
+		return err
+	}
+	if l.Having, err = asValue[HavingClause](node, "Having"); err != nil {
+		return err
+	}
+	if l.Order, err = a.clause(l, "Order", -1, l.Order); err != nil {
+		return err
+	}
+	if l.Limit, err = a.clause(l, "Limit", -1, l.Limit); err != nil {
+		return err
+	}
+	return a.clauses(l, "Additional", l.Additional)
+}
+
+func (a *applier) dml(l Node, with *With, where *WhereClause, additional Clauses) error {
+	var (
+		node Node
+		err  error
+	)
+	if *with, err = a.clause(l, "With", -1, *with); err != nil {
+		return err
+	}
+	if node, err = a.apply(l, "Where", -1, *where); err != nil {
+		return err
+	}
+	if *where, err = asValue[WhereClause](node, "Where"); err != nil {
+		return err
+	}
+	return a.clauses(l, "Additional", additional)
+}
+
+// Value nodes such as From can be replaced by a value or a pointer.
+func asValue[T any](node Node, name string) (T, error) {
+	switch n := node.(type) {
+	case T:
+		return n, nil
+	case *T:
+		if n != nil {
+			return *n, nil
+		}
+	}
+	var t T
+	return t, unexpectedNode(node, name)
+}
+
+func asClause(node Node, name string) (Clause, error) {
+	if node == nil {
+		return nil, nil
+	}
+	if c, ok := node.(Clause); ok {
+		return c, nil
+	}
+	return nil, unexpectedNode(node, name)
+}
+
+func asCondition(node Node, name string) (Condition, error) {
+	if node == nil {
+		return nil, nil
+	}
+	if c, ok := node.(Condition); ok {
+		return c, nil
+	}
+	return nil, unexpectedNode(node, name)
+}
+
+func unexpectedNode(node Node, name string) error {
+	return fmt.Errorf("unexpected node %T for %q", node, name)
+}
+
+// Get the names of tables referenced in FROM, JOIN, INSERT, UPDATE and DELETE, the names of common table expressions are excluded.
+// The sub queries in expressions are included, but tables in the SQL strings such as SimpleClause can not be found.
+func ReferencedTables(root Node) []string {
+	var (
+		names   []string
+		visited = make(map[string]bool)
+		ctes    = make(map[string]bool)
+	)
+	Inspect(root, func(node Node) bool {
+		switch n := node.(type) {
+		case *WithClause:
+			for _, t := range n.Tables {
+				if t.Clause != nil {
+					ctes[t.Name] = true
+				}
+			}
+		case From:
+			names = appendTableName(names, visited, n.Table)
+		case *From:
+			names = appendTableName(names, visited, n.Table)
+		case *Join:
+			names = appendTableName(names, visited, n.Table)
+		case *Update:
+			names = appendTableName(names, visited, TableByName(n.Table))
+		case *Delete:
+			names = appendTableName(names, visited, TableByName(n.Table))
+		case *Insert:
+			names = appendTableName(names, visited, TableByName(n.Table))
+		}
+		return true
+	})
+	res := names[:0]
+	for _, name := range names {
+		if !ctes[name] {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+func appendTableName(names []string, visited map[string]bool, t Table) []string {
+	if t.Clause != nil || t.Name == "" || visited[t.Name] {
+		return names
+	}
+	visited[t.Name] = true
+	return append(names, t.Name)
+}
+
+// Append the conditions to the WHERE clause of every DQL, UPDATE and DELETE in the tree,
+// including sub queries and common table expressions.
+// The statements are modified in place, see Apply, but the slices of conditions shared with the caller are not changed.
+func AppendWhere(root Clause, conditions ...Condition) (Clause, error) {
+	return ApplyClause(root, nil, func(cursor *Cursor) bool {
+		if _, where := statementOf(cursor.Node()); where != nil {
+			where.Conditions = append(slices.Clip(where.Conditions), conditions...)
+		}
+		return true
+	})
+}
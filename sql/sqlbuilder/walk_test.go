@@ -0,0 +1,174 @@
+package sqlbuilder_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/util/sql/sqlbuilder"
+	"github.com/everoute/util/sql/sqlbuilder/expr"
+)
+
+func newWalkDQL() *sqlbuilder.DQL {
+	sub := sqlbuilder.DQL{
+		From: sqlbuilder.FromTableName("b"),
+		Where: sqlbuilder.WhereClause{
+			Conditions: []sqlbuilder.Condition{
+				sqlbuilder.And(
+					sqlbuilder.NewCondition("x = ?", 1),
+					sqlbuilder.Not(sqlbuilder.NewCondition("y = ?", 2), sqlbuilder.OmitBrackets),
+					sqlbuilder.SaveBrackets,
+				),
+			},
+		},
+	}
+	return &sqlbuilder.DQL{
+		With: &sqlbuilder.WithClause{
+			Tables: []sqlbuilder.Table{
+				sqlbuilder.NameAsTable("a", &sqlbuilder.DQL{From: sqlbuilder.FromTableName("c")}),
+			},
+		},
+		From: sqlbuilder.From{sqlbuilder.TableAsName(&sub, "s")},
+		Additional: []sqlbuilder.Clause{
+			sqlbuilder.AddClauseLevel(&sqlbuilder.DQL{From: sqlbuilder.FromTableName("a")}, 1),
+		},
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Run("visit order", func(t *testing.T) {
+		RegisterTestingT(t)
+		var names []string
+		_, err := sqlbuilder.Apply(newWalkDQL(), func(cursor *sqlbuilder.Cursor) bool {
+			if c, ok := cursor.Node().(sqlbuilder.SimpleCondition); ok {
+				names = append(names, cursor.Name()+":"+c.Str)
+			}
+			return true
+		}, nil)
+		Expect(err).Should(Succeed())
+		Expect(names).Should(Equal([]string{"L:x = ?", "Condition:y = ?"}))
+	})
+	t.Run("replace condition", func(t *testing.T) {
+		RegisterTestingT(t)
+		root, err := sqlbuilder.ApplyClause(newWalkDQL(), func(cursor *sqlbuilder.Cursor) bool {
+			if c, ok := cursor.Node().(sqlbuilder.SimpleCondition); ok && c.Str == "y = ?" {
+				cursor.Replace(sqlbuilder.NewCondition("z = ?", 3))
+			}
+			return true
+		}, nil)
+		Expect(err).Should(Succeed())
+		sql, args, err := sqlbuilder.Build(root, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("WITH a AS ( SELECT * FROM c ) SELECT * FROM ( SELECT * FROM b WHERE (x = ? AND NOT z = ?) ) AS s SELECT * FROM a "))
+		Expect(args).Should(Equal([]sqlbuilder.Arg{1, 3}))
+	})
+	t.Run("skip children", func(t *testing.T) {
+		RegisterTestingT(t)
+		count := 0
+		_, err := sqlbuilder.Apply(newWalkDQL(), func(cursor *sqlbuilder.Cursor) bool {
+			count++
+			_, ok := cursor.Node().(sqlbuilder.From)
+			return !ok
+		}, nil)
+		Expect(err).Should(Succeed())
+		// DQL, With, DQL, Select, From, Where, Having, Select, From, Where, Having, Additional DQL, Select, From, Where, Having
+		Expect(count).Should(Equal(17))
+	})
+	t.Run("terminate", func(t *testing.T) {
+		RegisterTestingT(t)
+		count := 0
+		_, err := sqlbuilder.Apply(newWalkDQL(), nil, func(cursor *sqlbuilder.Cursor) bool {
+			count++
+			return false
+		})
+		Expect(err).Should(Succeed())
+		Expect(count).Should(Equal(1))
+	})
+	t.Run("replace with unexpected node", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, err := sqlbuilder.Apply(newWalkDQL(), func(cursor *sqlbuilder.Cursor) bool {
+			if _, ok := cursor.Node().(sqlbuilder.WhereClause); ok {
+				cursor.Replace(sqlbuilder.NewCondition("x"))
+			}
+			return true
+		}, nil)
+		Expect(err).ShouldNot(Succeed())
+	})
+	t.Run("condition root", func(t *testing.T) {
+		RegisterTestingT(t)
+		c, err := sqlbuilder.ApplyCondition(
+			sqlbuilder.Or(sqlbuilder.NewCondition("x"), sqlbuilder.Bracket(sqlbuilder.NewCondition("y")), sqlbuilder.OmitBrackets),
+			func(cursor *sqlbuilder.Cursor) bool {
+				if _, ok := cursor.Node().(sqlbuilder.BracketedCondition); ok {
+					cursor.Replace(sqlbuilder.NewCondition("z"))
+				}
+				return true
+			}, nil)
+		Expect(err).Should(Succeed())
+		buff := bytes.NewBufferString("")
+		Expect(c.Parse(buff, nil)).Should(Succeed())
+		Expect(buff.String()).Should(Equal("x OR z"))
+	})
+}
+
+func TestReferencedTables(t *testing.T) {
+	t.Run("tables", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(sqlbuilder.ReferencedTables(newWalkDQL())).Should(Equal([]string{"c", "b"}))
+	})
+	t.Run("sub queries in expressions", func(t *testing.T) {
+		RegisterTestingT(t)
+		sub := func(table string) *sqlbuilder.DQL {
+			return &sqlbuilder.DQL{Select: sqlbuilder.Select{Columns: []string{"id"}}, From: sqlbuilder.FromTableName(table)}
+		}
+		root := &sqlbuilder.DQL{
+			Select: sqlbuilder.Select{Expressions: []sqlbuilder.Expression{expr.As(sqlbuilder.NewSubQuery(sub("a")), "n")}},
+			From:   sqlbuilder.FromTableName("t"),
+			Where: sqlbuilder.WhereClause{Conditions: []sqlbuilder.Condition{
+				expr.Cmp(expr.Col("t.id"), "IN", sqlbuilder.NewSubQuery(sub("b"))),
+				sqlbuilder.Not(sqlbuilder.Exists(sub("c")), sqlbuilder.OmitBrackets),
+				expr.CaseWhen(sqlbuilder.Exists(sub("d")), expr.Raw("TRUE")),
+			}},
+			Group: sqlbuilder.GroupBy(expr.Coalesce(sqlbuilder.NewSubQuery(sub("e")))),
+			Order: sqlbuilder.OrderBy(sqlbuilder.Desc(sqlbuilder.NewSubQuery(sub("f")))),
+		}
+		Expect(sqlbuilder.ReferencedTables(root)).Should(Equal([]string{"a", "t", "b", "c", "d", "e", "f"}))
+
+		res, err := sqlbuilder.AppendWhere(root, sqlbuilder.NewCondition("tenant_id = ?", 7))
+		Expect(err).Should(Succeed())
+		sql, _, err := sqlbuilder.Build(res, sqlbuilder.Generic, sqlbuilder.Compact)
+		Expect(err).Should(Succeed())
+		Expect(sql).Should(Equal("SELECT ( SELECT id FROM a WHERE tenant_id = ? ) AS n FROM t " +
+			"WHERE t.id IN ( SELECT id FROM b WHERE tenant_id = ? ) AND NOT EXISTS ( SELECT id FROM c WHERE tenant_id = ? ) " +
+			"AND CASE WHEN EXISTS ( SELECT id FROM d WHERE tenant_id = ? ) THEN TRUE END AND tenant_id = ? " +
+			"GROUP BY COALESCE(( SELECT id FROM e WHERE tenant_id = ? )) ORDER BY ( SELECT id FROM f WHERE tenant_id = ? ) DESC "))
+	})
+}
+
+func TestAppendWhere(t *testing.T) {
+	RegisterTestingT(t)
+	root, err := sqlbuilder.AppendWhere(newWalkDQL(), sqlbuilder.NewCondition("tenant_id = ?", 7))
+	Expect(err).Should(Succeed())
+	sql, args, err := sqlbuilder.Build(root, sqlbuilder.Generic, sqlbuilder.Compact)
+	Expect(err).Should(Succeed())
+	Expect(sql).Should(Equal("WITH a AS ( SELECT * FROM c WHERE tenant_id = ? ) " +
+		"SELECT * FROM ( SELECT * FROM b WHERE (x = ? AND NOT y = ?) AND tenant_id = ? ) AS s WHERE tenant_id = ? " +
+		"SELECT * FROM a WHERE tenant_id = ? "))
+	Expect(args).Should(Equal([]sqlbuilder.Arg{7, 1, 2, 7, 7, 7}))
+
+	t.Run("shared conditions", func(t *testing.T) {
+		RegisterTestingT(t)
+		shared := make([]sqlbuilder.Condition, 1, 2)
+		shared[0] = sqlbuilder.NewCondition("x = ?", 1)
+		l1 := &sqlbuilder.DQL{From: sqlbuilder.FromTableName("a"), Where: sqlbuilder.WhereClause{Conditions: shared}}
+		l2 := &sqlbuilder.DQL{From: sqlbuilder.FromTableName("b"), Where: sqlbuilder.WhereClause{Conditions: shared}}
+		_, err := sqlbuilder.AppendWhere(l1, sqlbuilder.NewCondition("y = ?", 2))
+		Expect(err).Should(Succeed())
+		_, err = sqlbuilder.AppendWhere(l2, sqlbuilder.NewCondition("z = ?", 3))
+		Expect(err).Should(Succeed())
+		Expect(l1.Where.Conditions).Should(Equal([]sqlbuilder.Condition{shared[0], sqlbuilder.NewCondition("y = ?", 2)}))
+		Expect(l2.Where.Conditions).Should(Equal([]sqlbuilder.Condition{shared[0], sqlbuilder.NewCondition("z = ?", 3)}))
+		Expect(shared[:2][1]).Should(BeNil())
+	})
+}